@@ -0,0 +1,134 @@
+package pocketbase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// CountRecords returns the number of records of a collection matching the list options,
+// e.g. WithFilter, without downloading them: a single record is requested and the total
+// reported by the server is returned. The total is always counted, even when WithSkipTotal
+// is set as a client default with WithDefaultListOptions.
+//
+// Example:
+//
+//	drafts, err := client.CountRecords(ctx, "posts", pocketbase.WithFilter("status='draft'"))
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Printf("%d drafts", drafts)
+func (c *Client) CountRecords(ctx context.Context, collection string, opts ...ListOption) (int, error) {
+	options := c.newRecordListOptions(opts...)
+	ctx = withRequestUserAgent(ctx, options.UserAgent)
+
+	// Without the total there is nothing to count
+	options.SkipTotal = false
+	options.PerPage = 1
+	options.Fields = []string{"id"}
+	options.FieldsPreset = ""
+	options.Expand = nil
+	options.Sort = ""
+
+	resp, err := c.getRecordPage(ctx, collection, options, 1)
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.TotalItems, nil
+}
+
+// CountByField counts the records of a collection per distinct value of field,
+// similar to a "GROUP BY field" query. Records missing the field are counted under
+// the empty string key, and for multi-value fields (e.g. multiple relations or select
+// values) each value is counted separately.
+//
+// PocketBase has no server-side aggregation, so this fetches every matching record
+// (limited to the counted field) and tallies them client-side. Use WithFilter to keep
+// the number of downloaded records small on large collections.
+//
+// Example:
+//
+//	counts, err := client.CountByField(ctx, "posts", "category",
+//		pocketbase.WithFilter("status='published'"))
+//	if err != nil {
+//		return err
+//	}
+//	for category, count := range counts {
+//		fmt.Printf("%s: %d\n", category, count)
+//	}
+func (c *Client) CountByField(ctx context.Context, collection, field string, opts ...ListOption) (map[string]int, error) {
+	opts = slices.Concat(opts, []ListOption{WithListFields(field)})
+
+	records, err := c.GetAllRecords(ctx, collection, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, record := range records {
+		values, ok := record[field].([]any)
+		if !ok || len(values) == 0 {
+			counts[fieldValueKey(record[field])]++
+			continue
+		}
+		for _, value := range values {
+			counts[fieldValueKey(value)]++
+		}
+	}
+
+	return counts, nil
+}
+
+// GetAllRecordsGroupedBy fetches all records of a collection matching the list options,
+// like GetAllRecords, and groups them by the value of field. Records missing the field
+// are grouped under the empty string key. Like with CountByField, records with a
+// multi-value field are added to the group of each of their values.
+//
+// Example:
+//
+//	byCategory, err := client.GetAllRecordsGroupedBy(ctx, "posts", "category",
+//		pocketbase.WithSort("-created"))
+//	if err != nil {
+//		return err
+//	}
+//	for category, posts := range byCategory {
+//		fmt.Printf("%s: %d posts\n", category, len(posts))
+//	}
+func (c *Client) GetAllRecordsGroupedBy(ctx context.Context, collection, field string, opts ...ListOption) (map[string][]Record, error) {
+	records, err := c.GetAllRecords(ctx, collection, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string][]Record)
+	for _, record := range records {
+		values, ok := record[field].([]any)
+		if !ok || len(values) == 0 {
+			key := fieldValueKey(record[field])
+			groups[key] = append(groups[key], record)
+			continue
+		}
+		for _, value := range values {
+			key := fieldValueKey(value)
+			groups[key] = append(groups[key], record)
+		}
+	}
+
+	return groups, nil
+}
+
+// fieldValueKey converts a record field value to a string suitable as a map key.
+func fieldValueKey(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,297 @@
+package pocketbase
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// minRefreshBackoff is the initial wait before retrying a failed auto refresh.
+	minRefreshBackoff = time.Second
+	// maxRefreshBackoff caps the wait between auto refresh retries, and is also the
+	// interval at which a token without a known expiry is checked again.
+	maxRefreshBackoff = time.Minute
+)
+
+// AuthWithOAuth2Code authenticates a record in the given auth collection with an OAuth2
+// authorization code, as the final step of the OAuth2 flow. The codeVerifier and redirectURL
+// must match the ones used to build the provider authorization URL.
+// The optional createData is used to populate the record when the OAuth2 login creates a new
+// account, e.g. to set a username or other required fields. Pass nil to omit it.
+// On success, it stores the authentication token for subsequent requests and returns the auth record.
+//
+// Example:
+//
+//	record, err := client.AuthWithOAuth2Code(ctx, "users", "google", code, codeVerifier,
+//		"http://localhost:8080/callback", pocketbase.Record{"username": "alice"})
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Printf("Authenticated user: %s", record["email"])
+func (c *Client) AuthWithOAuth2Code(ctx context.Context, collection, provider, code, codeVerifier, redirectURL string, createData Record) (Record, error) {
+	result, err := c.AuthWithOAuth2CodeResult(ctx, collection, provider, code, codeVerifier, redirectURL, createData)
+	if err != nil {
+		return nil, err
+	}
+	return result.Record, nil
+}
+
+// AuthWithOAuth2CodeResult authenticates with an OAuth2 authorization code like
+// AuthWithOAuth2Code, returning the full result including the meta data of the OAuth2
+// provider. The meta data carries the provider profile (name, avatar...), which a first
+// time social login typically uses to populate the new account.
+//
+// Example:
+//
+//	result, err := client.AuthWithOAuth2CodeResult(ctx, "users", "google", code, codeVerifier,
+//		"http://localhost:8080/callback", nil)
+//	if err != nil {
+//		return err
+//	}
+//	if isNew, _ := result.Meta["isNew"].(bool); isNew {
+//		_, err = client.UpdateRecord(ctx, "users", result.Record["id"].(string), pocketbase.Record{
+//			"name": result.Meta["name"],
+//		})
+//	}
+func (c *Client) AuthWithOAuth2CodeResult(ctx context.Context, collection, provider, code, codeVerifier, redirectURL string, createData Record) (*AuthResult, error) {
+	endpoint := fmt.Sprintf("/api/collections/%s/auth-with-oauth2", url.PathEscape(collection))
+
+	body := map[string]any{
+		"provider":     provider,
+		"code":         code,
+		"codeVerifier": codeVerifier,
+		"redirectURL":  redirectURL,
+	}
+	if createData != nil {
+		body["createData"] = createData
+	}
+
+	var resp authResp
+	err := c.doRequest(ctx, "POST", endpoint, body, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	// Store the token for future requests
+	resp.Record = c.applyRecordHook(resp.Record)
+	c.setAuth(resp.Token, resp.Record)
+
+	return &AuthResult{Token: resp.Token, Record: resp.Record, Meta: resp.Meta}, nil
+}
+
+// AuthRefresh refreshes the current authentication token of a record in the given auth collection.
+// On success, it stores the new token for subsequent requests and returns the refreshed auth record.
+// Impersonation tokens are not refreshable.
+//
+// Example:
+//
+//	record, err := client.AuthRefresh(ctx, "users")
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Printf("Refreshed token for: %s", record["email"])
+func (c *Client) AuthRefresh(ctx context.Context, collection string) (Record, error) {
+	endpoint := fmt.Sprintf("/api/collections/%s/auth-refresh", url.PathEscape(collection))
+
+	var resp authResp
+	err := c.doRequest(ctx, "POST", endpoint, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	// Store the token for future requests
+	resp.Record = c.applyRecordHook(resp.Record)
+	c.setAuth(resp.Token, resp.Record)
+
+	return resp.Record, nil
+}
+
+// StartAutoRefresh starts a background goroutine that refreshes the authentication token
+// leadTime before it expires, based on the token's "exp" claim, and then reschedules itself
+// for the new token. Failed refreshes are retried with exponential backoff. After a
+// successful refresh, the next one waits at least a second, even when leadTime exceeds the
+// token lifetime or the server clock offset makes the new token look already due.
+// The goroutine stops when ctx is canceled or the client is closed.
+//
+// Example:
+//
+//	ctx, cancel := context.WithCancel(context.Background())
+//	defer cancel()
+//
+//	client.StartAutoRefresh(ctx, "users", 5*time.Minute)
+func (c *Client) StartAutoRefresh(ctx context.Context, collection string, leadTime time.Duration) {
+	go func() {
+		backoff := time.Duration(0)
+		refreshed := false
+
+		for {
+			var wait time.Duration
+			if backoff > 0 {
+				wait = backoff
+			} else if expiry, ok := c.TokenExpiry(); ok {
+				wait = expiry.Add(-leadTime).Sub(c.serverNow())
+				if refreshed {
+					// A token due right after its refresh must not cause a refresh loop
+					wait = max(wait, minRefreshBackoff)
+				}
+			} else {
+				// No token or no expiry yet, check again later
+				wait = maxRefreshBackoff
+			}
+
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-c.background.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			if _, ok := c.TokenExpiry(); !ok {
+				continue
+			}
+
+			if _, err := c.AuthRefresh(ctx, collection); err != nil {
+				backoff = min(max(backoff*2, minRefreshBackoff), maxRefreshBackoff)
+				continue
+			}
+			backoff = 0
+			refreshed = true
+		}
+	}()
+}
+
+// TokenExpiry returns the expiration time of the current authentication token,
+// read from its "exp" claim. The returned bool is false if there is no token or
+// its expiration can't be determined. The token signature is not verified.
+func (c *Client) TokenExpiry() (time.Time, bool) {
+	return tokenExpiry(c.GetToken())
+}
+
+// tokenExpiry reads the expiration time of a token from its "exp" claim.
+func tokenExpiry(token string) (time.Time, bool) {
+	claims, err := tokenClaims(token)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	return time.Unix(int64(exp), 0), true
+}
+
+// IsTokenExpired returns true if the current authentication token has expired.
+// Tokens without a readable expiration are considered expired.
+// The expiry is compared against the server time, see WithServerTimeOffset.
+func (c *Client) IsTokenExpired() bool {
+	expiry, ok := c.TokenExpiry()
+	return !ok || !c.serverNow().Before(expiry)
+}
+
+// IsAuthenticated returns true if the client holds a token that hasn't expired.
+// The token signature isn't verified, so the server may still reject it
+// (e.g. after a password change).
+//
+// Example:
+//
+//	if !client.IsAuthenticated() {
+//		http.Redirect(w, r, "/login", http.StatusFound)
+//		return
+//	}
+func (c *Client) IsAuthenticated() bool {
+	return c.GetToken() != "" && !c.IsTokenExpired()
+}
+
+// Well-known IDs of the system auth collections, which are the same on every PocketBase
+// v0.23+ instance.
+const (
+	superusersCollectionID = "pbc_3142635823"
+	usersCollectionID      = "_pb_users_auth_"
+)
+
+// AuthType returns the name of the auth collection the current token belongs to, e.g.
+// "users" or "_superusers", so generic tooling can branch on the privilege level. It is
+// read from the token claims without verifying the signature. The token only holds the
+// collection ID: the name is known for the system collections, for the admin tokens of
+// PocketBase versions before v0.23 ("_superusers") and for the collection of the stored
+// auth record, otherwise the collection ID is returned. It returns false when there is no
+// token or it isn't a readable auth token.
+//
+// Example:
+//
+//	if collection, ok := client.AuthType(); ok && collection == "_superusers" {
+//		logs, err := client.GetAllLogs(ctx)
+//		// ...
+//	}
+func (c *Client) AuthType() (string, bool) {
+	token := c.GetToken()
+	if token == "" {
+		return "", false
+	}
+
+	claims, err := tokenClaims(token)
+	if err != nil {
+		return "", false
+	}
+
+	switch claims["type"] {
+	case "admin":
+		return superusersCollection, true
+	case "auth", "authRecord":
+	default:
+		return "", false
+	}
+
+	collectionID, _ := claims["collectionId"].(string)
+	switch collectionID {
+	case "":
+		return "", false
+	case superusersCollectionID:
+		return superusersCollection, true
+	case usersCollectionID:
+		return "users", true
+	}
+
+	record := c.AuthRecord()
+	if name, _ := record["collectionName"].(string); name != "" && record["collectionId"] == collectionID {
+		return name, true
+	}
+	return collectionID, true
+}
+
+// serverNow returns the current time on the server clock, based on the local clock
+// and the offset configured with WithServerTimeOffset.
+func (c *Client) serverNow() time.Time {
+	return time.Now().Add(c.serverTimeOffset)
+}
+
+// tokenClaims decodes the payload of a JWT token without verifying its signature.
+func tokenClaims(token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid token format")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode token payload: %w", err)
+	}
+
+	var claims map[string]any
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("failed to parse token claims: %w", err)
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,208 @@
+package pocketbase
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// batchEndpoint is the PocketBase endpoint running several record operations in one transaction.
+const batchEndpoint = "/api/batch"
+
+// BatchBuilder collects record operations that are sent together with Send and run in a
+// single transaction: either all of them are applied or none. The batch API must be
+// enabled in the PocketBase settings.
+type BatchBuilder struct {
+	client     *Client
+	operations []batchOperation
+}
+
+// BatchResult is the response of a single operation of a batch.
+type BatchResult struct {
+	Status int    `json:"status"`
+	Body   Record `json:"body"` // nil for deletes
+}
+
+// BatchOption represents functional options for a single batch operation.
+type BatchOption func(*BatchOperationOptions)
+
+// BatchOperationOptions holds the options of a single batch operation.
+type BatchOperationOptions struct {
+	Uploads []FileUpload
+	QueryOptions
+}
+
+// WithBatchQuery applies query options such as WithExpand and WithFields to a single batch
+// operation. They are encoded in the query string of the operation URL, so a batched
+// create or update can return expanded relations like CreateRecord does.
+//
+// Example:
+//
+//	results, err := client.NewBatch().
+//		Create("posts", post, pocketbase.WithBatchQuery(pocketbase.WithExpand("author"))).
+//		Send(ctx)
+func WithBatchQuery(opts ...QueryOption) BatchOption {
+	return func(options *BatchOperationOptions) {
+		for _, opt := range opts {
+			opt(&options.QueryOptions)
+		}
+	}
+}
+
+// WithBatchFileUpload adds a file upload to a batch create or update, like WithFileUpload
+// does for CreateRecordWithFiles. The files are sent in a multipart body under the
+// "requests.{index}.{field}" part names expected by PocketBase.
+func WithBatchFileUpload(field string, files []FileData, options ...FileUploadModifier) BatchOption {
+	return func(opts *BatchOperationOptions) {
+		upload := FileUpload{
+			Field: field,
+			Files: files,
+		}
+
+		for _, option := range options {
+			option(&upload)
+		}
+
+		opts.Uploads = append(opts.Uploads, upload)
+	}
+}
+
+// batchOperation is a single request of the batch payload.
+type batchOperation struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   Record `json:"body,omitempty"`
+
+	uploads []FileUpload
+	query   QueryOptions
+}
+
+// batchPayload represents the request structure of the batch endpoint.
+type batchPayload struct {
+	Requests []batchOperation `json:"requests"`
+}
+
+// NewBatch returns a builder for sending several record operations in one transaction.
+//
+// Example:
+//
+//	avatar, _ := os.Open("avatar.png")
+//	defer avatar.Close()
+//
+//	results, err := client.NewBatch().
+//		Create("users", pocketbase.Record{"name": "Alice"},
+//			pocketbase.WithBatchFileUpload("avatar", []pocketbase.FileData{
+//				pocketbase.CreateFileData(avatar, "avatar.png"),
+//			})).
+//		Update("teams", "TEAM_ID", pocketbase.Record{"size": 5}).
+//		Delete("invites", "INVITE_ID").
+//		Send(ctx)
+func (c *Client) NewBatch() *BatchBuilder {
+	return &BatchBuilder{client: c}
+}
+
+// Create adds the creation of a record to the batch.
+func (b *BatchBuilder) Create(collection string, record Record, opts ...BatchOption) *BatchBuilder {
+	return b.add("POST", recordsEndpoint(collection), record, opts)
+}
+
+// Update adds the update of a record to the batch.
+func (b *BatchBuilder) Update(collection, recordID string, record Record, opts ...BatchOption) *BatchBuilder {
+	return b.add("PATCH", recordsEndpoint(collection)+"/"+url.PathEscape(recordID), record, opts)
+}
+
+// Delete adds the deletion of a record to the batch.
+func (b *BatchBuilder) Delete(collection, recordID string, opts ...BatchOption) *BatchBuilder {
+	return b.add("DELETE", recordsEndpoint(collection)+"/"+url.PathEscape(recordID), nil, opts)
+}
+
+// add appends an operation to the batch. File deletions are sent in the JSON body
+// with the "field-" modifier, as multipart parts can only carry new files.
+func (b *BatchBuilder) add(method, endpoint string, record Record, opts []BatchOption) *BatchBuilder {
+	options := &BatchOperationOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	op := batchOperation{
+		Method:  method,
+		URL:     endpoint,
+		Body:    record,
+		uploads: options.Uploads,
+		query:   options.QueryOptions,
+	}
+
+	for _, upload := range options.Uploads {
+		if len(upload.Delete) == 0 {
+			continue
+		}
+		body := make(Record, len(op.Body)+1)
+		for key, value := range op.Body {
+			body[key] = value
+		}
+		body[upload.Field+"-"] = upload.Delete
+		op.Body = body
+	}
+
+	b.operations = append(b.operations, op)
+	return b
+}
+
+// Send runs the batch and returns the result of each operation, in order.
+// Without file uploads the batch is sent as JSON, otherwise as a multipart body with
+// the operations in the "@jsonPayload" field and the files in "requests.{index}.{field}" parts.
+// If any operation fails, nothing is applied and an *APIError is returned.
+func (b *BatchBuilder) Send(ctx context.Context) ([]BatchResult, error) {
+	if len(b.operations) == 0 {
+		return nil, fmt.Errorf("batch has no operations")
+	}
+
+	payload := batchPayload{Requests: make([]batchOperation, len(b.operations))}
+	for i, op := range b.operations {
+		params, err := b.client.queryParams(&op.query)
+		if err != nil {
+			return nil, err
+		}
+		if len(params) > 0 {
+			op.URL += "?" + params.Encode()
+		}
+		payload.Requests[i] = op
+	}
+
+	var uploads []FileUpload
+	for i, op := range b.operations {
+		for _, upload := range op.uploads {
+			if len(upload.Files) == 0 {
+				continue
+			}
+			field := "requests." + strconv.Itoa(i) + "." + upload.Field
+			if upload.Append {
+				field += "+"
+			}
+			uploads = append(uploads, FileUpload{Field: field, Files: upload.Files})
+		}
+	}
+
+	var body any = payload
+	if len(uploads) > 0 {
+		jsonPayload, err := b.client.jsonMarshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal batch payload: %w", err)
+		}
+		body = &FileUploadOptions{
+			Uploads: uploads,
+			Data:    Record{"@jsonPayload": string(jsonPayload)},
+		}
+	}
+
+	var results []BatchResult
+	if err := b.client.doRequest(ctx, "POST", batchEndpoint, body, &results); err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Body = b.client.applyRecordHook(results[i].Body)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,114 @@
+package pocketbase
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// redactedValue replaces the value of sensitive fields in logged bodies.
+const redactedValue = "[REDACTED]"
+
+// sensitiveFields are the body fields whose values are never logged, matched case-insensitively.
+var sensitiveFields = []string{
+	"password",
+	"passwordConfirm",
+	"oldPassword",
+	"token",
+	"codeVerifier",
+	"otpId",
+	"secret",
+}
+
+// bodyLogger writes request and response bodies to a writer for debugging.
+type bodyLogger struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// logRequest writes a request line and its redacted body.
+func (l *bodyLogger) logRequest(req *http.Request, body []byte) {
+	l.write(fmt.Sprintf("--> %s %s", req.Method, req.URL.Redacted()), body)
+}
+
+// logResponse writes a response line and its redacted body, leaving the response body
+// readable for the caller.
+func (l *bodyLogger) logResponse(req *http.Request, resp *http.Response) {
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(io.MultiReader(bytes.NewReader(data), errReader{err}))
+
+	l.write(fmt.Sprintf("<-- %d %s %s", resp.StatusCode, req.Method, req.URL.Redacted()), data)
+}
+
+// write writes a log entry made of a header line and a redacted body.
+func (l *bodyLogger) write(line string, body []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	fmt.Fprintln(l.w, line)
+	if len(body) > 0 {
+		fmt.Fprintf(l.w, "%s\n", redactBody(body))
+	}
+}
+
+// redactBody replaces the values of sensitive fields in a JSON body. Bodies that aren't
+// JSON (e.g. file uploads) are summarized by their size instead of being written.
+func redactBody(body []byte) []byte {
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Appendf(nil, "[%d bytes]", len(body))
+	}
+
+	redacted, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return fmt.Appendf(nil, "[%d bytes]", len(body))
+	}
+	return redacted
+}
+
+// redactValue replaces sensitive fields in nested JSON objects and arrays.
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if isSensitiveField(key) {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = redactValue(field)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return value
+}
+
+// isSensitiveField reports whether the value of a body field must not be logged.
+func isSensitiveField(name string) bool {
+	for _, field := range sensitiveFields {
+		if strings.EqualFold(name, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// errReader returns err once the buffered part of a body has been read, so a failed
+// read of a logged response body is still reported to the caller.
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read([]byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	return 0, io.EOF
+}
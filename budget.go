@@ -0,0 +1,86 @@
+package pocketbase
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DeadlineBudget splits the remaining deadline of a context across a known number of
+// sequential calls, so that each call gets a fair share of the time left and fails fast
+// instead of a slow call leaving almost no time for the last ones. Time left unused by
+// a fast call is carried over to the following calls.
+type DeadlineBudget struct {
+	client *Client
+	ctx    context.Context
+
+	mu        sync.Mutex
+	callsLeft int
+}
+
+// NewDeadlineBudget returns a budget sharing the deadline of ctx between calls sequential
+// calls. Without a deadline on ctx, calls are not limited. A warning is logged (see
+// WithLogger) when a call uses its whole share of the budget.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+//	defer cancel()
+//
+//	budget := client.NewDeadlineBudget(ctx, 3)
+//
+//	callCtx, done := budget.Next()
+//	user, err := client.GetRecord(callCtx, "users", userID)
+//	done()
+//	if err != nil {
+//		return err
+//	}
+//
+//	callCtx, done = budget.Next()
+//	posts, err := client.GetAllRecords(callCtx, "posts", pocketbase.WithFilter(filter))
+//	done()
+func (c *Client) NewDeadlineBudget(ctx context.Context, calls int) *DeadlineBudget {
+	return &DeadlineBudget{
+		client:    c,
+		ctx:       ctx,
+		callsLeft: max(calls, 1),
+	}
+}
+
+// Remaining returns the time left until the deadline of the budget context.
+// The returned bool is false if the context has no deadline.
+func (b *DeadlineBudget) Remaining() (time.Duration, bool) {
+	deadline, ok := b.ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(deadline), true
+}
+
+// Next returns the context for the next call, whose deadline is its share of the remaining
+// budget: the time left divided by the number of calls left. The returned function must be
+// called when the call returns, to release the context and account for the time used.
+// Calls beyond the number the budget was created for get all of the remaining time.
+func (b *DeadlineBudget) Next() (context.Context, context.CancelFunc) {
+	b.mu.Lock()
+	calls := b.callsLeft
+	b.callsLeft = max(b.callsLeft-1, 1)
+	b.mu.Unlock()
+
+	remaining, ok := b.Remaining()
+	if !ok {
+		return context.WithCancel(b.ctx)
+	}
+
+	share := remaining / time.Duration(calls)
+	ctx, cancel := context.WithTimeout(b.ctx, share)
+	start := time.Now()
+
+	return ctx, func() {
+		cancel()
+		if elapsed := time.Since(start); elapsed >= share && calls > 1 {
+			b.client.logger.Warn("pocketbase: call used its whole share of the deadline budget",
+				"share", share, "elapsed", elapsed, "calls_left", calls-1)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package pocketbase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// CancelAll aborts every request of the client in flight and makes new requests fail
+// fast with an error wrapping ErrRequestsCanceled, until ResumeRequests is called. It is
+// meant for a hard stop, e.g. when the application shuts down or the user navigates away,
+// without having to thread a shared context through every call. Unlike Close, the client
+// can be used again afterwards. Realtime subscriptions are not affected, use their
+// context or Close to end them.
+//
+// Example:
+//
+//	go func() {
+//		<-shutdown
+//		client.CancelAll()
+//	}()
+func (c *Client) CancelAll() {
+	c.cancelMu.Lock()
+	defer c.cancelMu.Unlock()
+	c.cancelRequests()
+}
+
+// ResumeRequests allows requests again after CancelAll. Requests canceled by CancelAll
+// are not resumed.
+func (c *Client) ResumeRequests() {
+	c.cancelMu.Lock()
+	defer c.cancelMu.Unlock()
+	if c.requestsCtx.Err() != nil {
+		c.requestsCtx, c.cancelRequests = context.WithCancel(context.Background())
+	}
+}
+
+// requestContext derives the context of a request from ctx, canceling it when CancelAll is
+// called. The returned stop function releases the context once the request is done. An
+// error wrapping ErrRequestsCanceled is returned when CancelAll was called and requests
+// haven't been resumed since.
+func (c *Client) requestContext(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	c.cancelMu.Lock()
+	requestsCtx := c.requestsCtx
+	c.cancelMu.Unlock()
+
+	if requestsCtx.Err() != nil {
+		return nil, nil, ErrRequestsCanceled
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	stopAfter := context.AfterFunc(requestsCtx, func() {
+		cancel(ErrRequestsCanceled)
+	})
+	return ctx, func() {
+		stopAfter()
+		cancel(nil)
+	}, nil
+}
+
+// canceledError wraps err with ErrRequestsCanceled when the request failed because
+// CancelAll canceled its context.
+func canceledError(ctx context.Context, err error) error {
+	if err != nil && errors.Is(context.Cause(ctx), ErrRequestsCanceled) && !errors.Is(err, ErrRequestsCanceled) {
+		return fmt.Errorf("%w: %w", ErrRequestsCanceled, err)
+	}
+	return err
+}
+
+// stopOnCloseBody releases the context of a streamed response when its body is closed.
+type stopOnCloseBody struct {
+	io.ReadCloser
+	stop context.CancelFunc
+}
+
+func (b *stopOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.stop()
+	return err
+}
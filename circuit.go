@@ -0,0 +1,115 @@
+package pocketbase
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without sending the request while the circuit breaker
+// configured with WithCircuitBreaker is open.
+var ErrCircuitOpen = errors.New("pocketbase: circuit breaker is open")
+
+// circuitState is the state of a circuit breaker.
+type circuitState int
+
+const (
+	circuitClosed   circuitState = iota // Requests are sent normally
+	circuitOpen                         // Requests fail fast until the cooldown has passed
+	circuitHalfOpen                     // A single trial request is in flight
+)
+
+// circuitBreaker stops sending requests after consecutive server failures, so callers
+// fail fast instead of piling up slow requests while the server is down.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    circuitState
+	failures int
+	openedAt time.Time
+}
+
+// allow returns ErrCircuitOpen if a request may not be sent. Once the cooldown has
+// passed, a single trial request is allowed through to probe the server.
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = circuitHalfOpen
+		return nil
+	case circuitHalfOpen:
+		return ErrCircuitOpen
+	default:
+		return nil
+	}
+}
+
+// circuitOutcome is what a request tells about the health of the server.
+type circuitOutcome int
+
+const (
+	outcomeSuccess circuitOutcome = iota // The server answered
+	outcomeFailure                       // The server is unreachable or failing
+	outcomeNone                          // The request was canceled or never sent
+)
+
+// record updates the breaker with the outcome of a request allowed by allow. A request
+// without outcome doesn't prove anything: a half-open breaker goes back to open, with
+// its cooldown already passed so that the next request is the trial, and the failure
+// count is kept.
+func (b *circuitBreaker) record(outcome circuitOutcome) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch outcome {
+	case outcomeSuccess:
+		b.state = circuitClosed
+		b.failures = 0
+	case outcomeNone:
+		if b.state == circuitHalfOpen {
+			b.state = circuitOpen
+		}
+	case outcomeFailure:
+		b.failures++
+		if b.state == circuitHalfOpen || b.failures >= b.threshold {
+			b.state = circuitOpen
+			b.openedAt = time.Now()
+		}
+	}
+}
+
+// requestOutcome classifies the error of a request for the circuit breaker. Network
+// errors, timeouts and 5xx responses are failures, while other responses including 4xx
+// are successes. Requests canceled by the caller and errors raised before the request
+// was sent (e.g. ErrInsecureAuth or an encoding error) have no outcome.
+func requestOutcome(err error) circuitOutcome {
+	if err == nil {
+		return outcomeSuccess
+	}
+	if errors.Is(err, context.Canceled) {
+		return outcomeNone
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.Status >= 500 {
+			return outcomeFailure
+		}
+		return outcomeSuccess
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return outcomeFailure
+	}
+	return outcomeNone
+}
@@ -3,26 +3,159 @@ package pocketbase
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"maps"
+	"math/rand/v2"
 	"mime/multipart"
+	"net"
 	"net/http"
+	"net/http/httptrace"
+	"net/textproto"
 	"net/url"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
+)
+
+// defaultUserAgent is the User-Agent header sent when no custom one is configured.
+const defaultUserAgent = "pocketbase-go/1.0"
+
+const (
+	// defaultPerPage is the PocketBase default number of records per page.
+	defaultPerPage = 30
+	// maxPerPage is the maximum number of records per page allowed by PocketBase.
+	maxPerPage = 1000
+)
+
+const (
+	// minRetryBackoff is the wait before the first retry of a failed request.
+	minRetryBackoff = 100 * time.Millisecond
+	// maxRetryBackoff caps the wait between retries of a failed request.
+	maxRetryBackoff = 5 * time.Second
+)
+
+const (
+	// maxRateLimitRetries is the number of times a page request is retried after a 429 response.
+	maxRateLimitRetries = 3
+	// defaultRateLimitWait is the wait before retrying a 429 response without a Retry-After header.
+	defaultRateLimitWait = time.Second
+	// defaultMaxPages caps the number of pages fetched by a single paginated call.
+	defaultMaxPages = 10000
 )
 
 // Client represents a PocketBase API client.
 type Client struct {
-	BaseURL    string
-	HTTPClient *http.Client
-	userAgent  string
+	// BaseURL is the server URL requests are sent to. Use SetBaseURL to change it once
+	// the client is in use, as writing the field directly isn't safe for concurrent use.
+	BaseURL     string
+	HTTPClient  *http.Client
+	userAgent   string
+	tokenHeader string
+	contentType string
+
+	// defaultPerPage is the page size used by list requests without WithPerPage
+	defaultPerPage int
+	// defaultListOptions are applied before the per-call options of record list requests
+	defaultListOptions []ListOption
+
+	// fieldPresets holds the named field selections registered with WithFieldPreset
+	fieldPresets map[string][]string
+
+	// JSON encoding functions used for request and response bodies
+	jsonMarshal   func(any) ([]byte, error)
+	jsonUnmarshal func([]byte, any) error
+
+	// redirectPolicy overrides the HTTP client CheckRedirect function when set
+	redirectPolicy func(req *http.Request, via []*http.Request) error
+
+	logger *slog.Logger
+
+	// bodyLog writes request and response bodies when set with WithBodyLogging
+	bodyLog *bodyLogger
+
+	// allowDestructiveOnRemote allows operations like truncating a collection on remote servers
+	allowDestructiveOnRemote bool
+
+	// requireHTTPSForAuth refuses to send the token over plain HTTP to remote hosts
+	requireHTTPSForAuth bool
+
+	// skipTLSForLocalhost skips certificate verification for localhost, see WithSkipTLSForLocalhost
+	skipTLSForLocalhost bool
+
+	// headers are the custom headers set with WithHeader and WithHeaders
+	headers http.Header
+
+	// clientTrace creates the optional httptrace hooks attached to each request
+	clientTrace func() *httptrace.ClientTrace
+
+	// serverTimeOffset is the difference between the server and the local clock,
+	// applied when checking token expiry
+	serverTimeOffset time.Duration
+
+	// maxRetries is the number of times a failed idempotent request is retried
+	maxRetries int
+	// backoff returns the wait before a retry, DefaultBackoff is used when nil
+	backoff func(retry int) time.Duration
+	// retryBudget caps the total time spent on the attempts of a request when positive
+	retryBudget time.Duration
+
+	// circuitBreaker fails requests fast during outages when enabled with WithCircuitBreaker
+	circuitBreaker *circuitBreaker
+
+	// strictDecoding rejects unknown fields when decoding into user-defined types
+	strictDecoding bool
+
+	// recordHook post-processes the returned records, see WithRecordHook
+	recordHook func(Record) Record
+
+	// keepAliveInterval is the interval of the health pings sent by WithKeepAlivePing
+	keepAliveInterval time.Duration
+
+	// requestsCtx is canceled by CancelAll to abort the requests in flight
+	cancelMu       sync.Mutex
+	requestsCtx    context.Context
+	cancelRequests context.CancelFunc
+
+	// background is canceled to stop the goroutines owned by the client
+	background     context.Context
+	stopBackground context.CancelFunc
+	closeOnce      sync.Once
+
+	// superuserStore shares superuser tokens with other clients when set
+	superuserStore *SuperuserTokenStore
+
+	// initErr records an invalid option value, returned by every request
+	initErr error
+
+	// httpClientOptions are applied to a copy of the HTTP client once all options have
+	// run, so they compose with WithHTTPClient regardless of the option order
+	httpClientOptions []func(*http.Client)
+
+	// transportOptions are applied to a clone of the HTTP client transport once all
+	// options have run, so they compose regardless of the option order
+	transportOptions []func(*http.Transport)
+
+	// registry holds the types registered with RegisterType
+	registry typeRegistry
+
+	// rateLimit holds the last rate limit headers received, see RateLimitState
+	rateLimitMu       sync.Mutex
+	rateLimit         RateLimitState
+	rateLimitObserver func(RateLimitState)
+
+	// baseURLMu guards BaseURL against concurrent SetBaseURL calls
+	baseURLMu sync.RWMutex
 
 	// Thread-safe token storage
-	tokenMu sync.RWMutex
-	token   string
+	tokenMu    sync.RWMutex
+	token      string
+	authRecord Record
 }
 
 // NewClient creates a new PocketBase client with the given base URL and options.
@@ -36,25 +169,184 @@ type Client struct {
 //		pocketbase.WithUserAgent("MyApp/1.0"))
 func NewClient(baseURL string, opts ...Option) *Client {
 	client := &Client{
-		BaseURL:    strings.TrimSuffix(baseURL, "/"),
-		HTTPClient: &http.Client{},
-		userAgent:  "pocketbase-go/1.0",
+		BaseURL:        strings.TrimSuffix(baseURL, "/"),
+		HTTPClient:     &http.Client{},
+		userAgent:      defaultUserAgent,
+		tokenHeader:    "Authorization",
+		contentType:    "application/json",
+		defaultPerPage: defaultPerPage,
+		logger:         slog.New(slog.DiscardHandler),
+		jsonMarshal:    json.Marshal,
+		jsonUnmarshal:  json.Unmarshal,
 	}
 
 	for _, opt := range opts {
 		opt(client)
 	}
 
+	if len(client.httpClientOptions) > 0 {
+		httpClient := *client.HTTPClient
+		for _, opt := range client.httpClientOptions {
+			opt(&httpClient)
+		}
+		client.HTTPClient = &httpClient
+	}
+	if len(client.transportOptions) > 0 {
+		client.applyTransportOptions()
+	}
+	if client.skipTLSForLocalhost {
+		client.applyLocalhostTLS()
+	}
+
+	client.background, client.stopBackground = context.WithCancel(context.Background())
+	client.requestsCtx, client.cancelRequests = context.WithCancel(context.Background())
+	if client.keepAliveInterval > 0 {
+		go client.keepAlive(client.keepAliveInterval)
+	}
+
 	return client
 }
 
+// Close releases the resources of the client: it stops the background goroutines
+// (auto refresh, keep-alive pings), closes the open realtime subscriptions and closes
+// the idle pooled connections. Requests in flight are not interrupted. Close is
+// idempotent and safe to call concurrently; the client shouldn't be used afterwards.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("http://localhost:8090", pocketbase.WithKeepAlivePing(time.Minute))
+//	defer client.Close()
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() {
+		c.stopBackground()
+		c.HTTPClient.CloseIdleConnections()
+	})
+	return nil
+}
+
+// applyTransportOptions applies the transport options to a clone of the HTTP client
+// and its transport, so a caller-provided client is never modified.
+// Custom http.RoundTripper implementations can't be configured and are left as is.
+func (c *Client) applyTransportOptions() {
+	var transport *http.Transport
+	switch t := c.HTTPClient.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		c.logger.Warn("pocketbase: transport options ignored for a custom http.RoundTripper",
+			"transport", fmt.Sprintf("%T", t))
+		return
+	}
+
+	for _, opt := range c.transportOptions {
+		opt(transport)
+	}
+
+	httpClient := *c.HTTPClient
+	httpClient.Transport = transport
+	c.HTTPClient = &httpClient
+}
+
+// applyLocalhostTLS routes requests to localhost through a clone of the HTTP client
+// transport that skips certificate verification, keeping the original transport for the
+// other hosts. Custom http.RoundTripper implementations are left as is.
+func (c *Client) applyLocalhostTLS() {
+	var transport *http.Transport
+	switch t := c.HTTPClient.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport)
+	case *http.Transport:
+		transport = t
+	default:
+		c.logger.Warn("pocketbase: WithSkipTLSForLocalhost ignored for a custom http.RoundTripper",
+			"transport", fmt.Sprintf("%T", t))
+		return
+	}
+
+	local := transport.Clone()
+	if local.TLSClientConfig == nil {
+		local.TLSClientConfig = &tls.Config{}
+	}
+	local.TLSClientConfig.InsecureSkipVerify = true
+
+	httpClient := *c.HTTPClient
+	httpClient.Transport = &localhostTransport{remote: transport, local: local}
+	c.HTTPClient = &httpClient
+}
+
+// localhostTransport sends the requests to localhost with the local transport and the
+// other requests with the remote transport.
+type localhostTransport struct {
+	remote http.RoundTripper
+	local  http.RoundTripper
+}
+
+func (t *localhostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if isLocalhost(req.URL.Hostname()) {
+		return t.local.RoundTrip(req)
+	}
+	return t.remote.RoundTrip(req)
+}
+
+// CloseIdleConnections closes the idle connections of both transports, so Close keeps
+// working with WithSkipTLSForLocalhost.
+func (t *localhostTransport) CloseIdleConnections() {
+	for _, transport := range []http.RoundTripper{t.remote, t.local} {
+		if closer, ok := transport.(interface{ CloseIdleConnections() }); ok {
+			closer.CloseIdleConnections()
+		}
+	}
+}
+
+// SetBaseURL changes the server URL requests are sent to, e.g. when it is discovered
+// after the client was created. Like in NewClient, a trailing slash is removed. The URL
+// must be absolute with an http or https scheme, otherwise an error is returned and the
+// base URL is left unchanged.
+//
+// It is safe to call concurrently with requests: requests already started keep the
+// previous URL, later ones use the new one. The authentication token is kept, so clear it
+// with SetToken("") when the new server doesn't accept it. Open realtime subscriptions
+// stay connected to the previous server until they are reconnected.
+//
+// Example:
+//
+//	addr, err := discover("pocketbase")
+//	if err != nil {
+//		return err
+//	}
+//	if err := client.SetBaseURL("http://" + addr); err != nil {
+//		return err
+//	}
+func (c *Client) SetBaseURL(baseURL string) error {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid base URL %q: %w", baseURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid base URL %q: must be an absolute http or https URL", baseURL)
+	}
+
+	c.baseURLMu.Lock()
+	defer c.baseURLMu.Unlock()
+	c.BaseURL = strings.TrimSuffix(baseURL, "/")
+	return nil
+}
+
+// baseURL returns the server URL requests are sent to.
+func (c *Client) baseURL() string {
+	c.baseURLMu.RLock()
+	defer c.baseURLMu.RUnlock()
+	return c.BaseURL
+}
+
 // SetToken manually sets the authentication token for API requests.
 // This is useful when you have a token from previous authentication
-// or from another source.
+// or from another source. The stored auth record is cleared, as it is unknown
+// which record the token belongs to.
 func (c *Client) SetToken(token string) {
-	c.tokenMu.Lock()
-	defer c.tokenMu.Unlock()
-	c.token = token
+	c.setAuth(token, nil)
 }
 
 // GetToken returns the current authentication token.
@@ -64,8 +356,45 @@ func (c *Client) GetToken() string {
 	return c.token
 }
 
+// AuthRecord returns a copy of the record of the last successful authentication, or nil
+// if the client isn't authenticated or the token was set manually with SetToken. The copy
+// is shallow: nested values such as "expand" are shared and must not be modified.
+func (c *Client) AuthRecord() Record {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+	return maps.Clone(c.authRecord)
+}
+
+// IsAuthRecordVerified returns true if the stored auth record has a verified email,
+// e.g. to show a "please verify your email" notice. It returns false when there is no
+// stored auth record, see AuthRecord.
+//
+// Example:
+//
+//	if _, err := client.AuthenticateWithPassword(ctx, "users", email, password); err != nil {
+//		return err
+//	}
+//	if !client.IsAuthRecordVerified() {
+//		showVerifyEmailNotice()
+//	}
+func (c *Client) IsAuthRecordVerified() bool {
+	verified, _ := c.AuthRecord()["verified"].(bool)
+	return verified
+}
+
+// setAuth stores the authentication token together with a copy of the record it belongs
+// to, so the record returned to the caller can be modified safely.
+func (c *Client) setAuth(token string, record Record) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	c.token = token
+	c.authRecord = maps.Clone(record)
+}
+
 // AuthenticateWithPassword authenticates with PocketBase using username/email and password.
 // On success, it stores the authentication token for subsequent requests and returns the user record.
+// With WithRetry, network errors and 502, 503 and 504 responses are retried like for
+// idempotent requests, while a 400 for invalid credentials is returned right away.
 //
 // Example:
 //
@@ -76,21 +405,23 @@ func (c *Client) GetToken() string {
 //	}
 //	fmt.Printf("Authenticated user: %s", record["email"])
 func (c *Client) AuthenticateWithPassword(ctx context.Context, collection, identity, password string) (Record, error) {
-	endpoint := fmt.Sprintf("/api/collections/%s/auth-with-password", collection)
+	endpoint := fmt.Sprintf("/api/collections/%s/auth-with-password", url.PathEscape(collection))
 
 	body := map[string]string{
 		"identity": identity,
 		"password": password,
 	}
 
+	// Authenticating has no side effects, so transient failures are retried with WithRetry
 	var resp authResp
-	err := c.doRequest(ctx, "POST", endpoint, body, &resp)
+	err := c.doRequest(withRetryable(ctx), "POST", endpoint, body, &resp)
 	if err != nil {
 		return nil, err
 	}
 
 	// Store the token for future requests
-	c.SetToken(resp.Token)
+	resp.Record = c.applyRecordHook(resp.Record)
+	c.setAuth(resp.Token, resp.Record)
 
 	return resp.Record, nil
 }
@@ -107,13 +438,35 @@ func (c *Client) AuthenticateWithPassword(ctx context.Context, collection, ident
 //		return err
 //	}
 //	fmt.Printf("Authenticated superuser: %s", superuser["email"])
+//
+// With WithSharedSuperuserAuth, a valid token already obtained by another client for the
+// same credentials is reused instead of authenticating again.
 func (c *Client) AuthenticateAsSuperuser(ctx context.Context, email, password string) (Record, error) {
-	return c.AuthenticateWithPassword(ctx, "_superusers", email, password)
+	if c.superuserStore == nil {
+		return c.AuthenticateWithPassword(ctx, superusersCollection, email, password)
+	}
+
+	key := superuserStoreKey(c.baseURL(), email, password)
+	auth, err := c.superuserStore.authenticate(key, c.sharedTokenValid, func() (superuserAuth, error) {
+		record, err := c.AuthenticateWithPassword(ctx, superusersCollection, email, password)
+		return superuserAuth{token: c.GetToken(), record: record}, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.setAuth(auth.token, auth.record)
+	return auth.record, nil
 }
 
 // Impersonate allows superusers to impersonate another user by generating a non-refreshable auth token.
-// This method requires superuser authentication. The generated token has a custom duration (in seconds)
-// or falls back to the default collection auth token duration if duration is 0 or not provided.
+// This method requires superuser authentication. The generated token has a custom duration (in seconds),
+// or the default collection auth token duration if duration is 0. A negative duration returns an error
+// without sending the request.
+//
+// Audit metadata such as WithImpersonationReason is forwarded in the request body for
+// server hooks to log, and the result records the ID of the impersonating superuser.
+// Query options such as WithExpand are passed with WithImpersonateQuery.
 //
 // Example:
 //
@@ -132,28 +485,33 @@ func (c *Client) AuthenticateAsSuperuser(ctx context.Context, email, password st
 //	// The result contains the impersonation token and user record
 //	fmt.Printf("Impersonation token: %s\n", result.Token)
 //	fmt.Printf("Impersonated user: %s\n", result.Record["email"])
-func (c *Client) Impersonate(ctx context.Context, collection, recordID string, duration int, opts ...QueryOption) (*ImpersonateResult, error) {
-	options := &QueryOptions{}
+func (c *Client) Impersonate(ctx context.Context, collection, recordID string, duration int, opts ...ImpersonateOption) (*ImpersonateResult, error) {
+	if duration < 0 {
+		return nil, fmt.Errorf("impersonation duration must not be negative, got %d", duration)
+	}
+
+	options := &ImpersonateOptions{}
 	for _, opt := range opts {
 		opt(options)
 	}
+	ctx = withRequestUserAgent(ctx, options.UserAgent)
 
-	endpoint := fmt.Sprintf("/api/collections/%s/impersonate/%s", collection, recordID)
+	endpoint := fmt.Sprintf("/api/collections/%s/impersonate/%s", url.PathEscape(collection), url.PathEscape(recordID))
 
 	// Build query parameters
-	params := url.Values{}
-	if len(options.Expand) > 0 {
-		params.Set("expand", strings.Join(options.Expand, ","))
-	}
-	if len(options.Fields) > 0 {
-		params.Set("fields", strings.Join(options.Fields, ","))
+	params, err := c.queryParams(&options.QueryOptions)
+	if err != nil {
+		return nil, err
 	}
 	if len(params) > 0 {
 		endpoint += "?" + params.Encode()
 	}
 
-	// Prepare request body with optional duration
+	// Prepare request body with the optional audit metadata and duration
 	body := make(map[string]any)
+	for key, value := range options.Metadata {
+		body[key] = value
+	}
 	if duration > 0 {
 		body["duration"] = duration
 	}
@@ -164,17 +522,40 @@ func (c *Client) Impersonate(ctx context.Context, collection, recordID string, d
 	}
 
 	var resp impersonateResp
-	err := c.doRequest(ctx, "POST", endpoint, bodyToSend, &resp)
+	err = c.doRequest(ctx, "POST", endpoint, bodyToSend, &resp)
 	if err != nil {
 		return nil, err
 	}
 
+	var impersonatorID string
+	if claims, err := tokenClaims(c.requestToken(ctx)); err == nil {
+		impersonatorID, _ = claims["id"].(string)
+	}
+
 	return &ImpersonateResult{
-		Token:  resp.Token,
-		Record: resp.Record,
+		Token:          resp.Token,
+		Record:         c.applyRecordHook(resp.Record),
+		ImpersonatorID: impersonatorID,
 	}, nil
 }
 
+// NewClient creates a new client authenticated with the impersonation token,
+// so requests can be made as the impersonated record right away.
+//
+// Example:
+//
+//	result, err := client.Impersonate(ctx, "users", "USER_ID", 3600)
+//	if err != nil {
+//		return err
+//	}
+//	userClient := result.NewClient("http://localhost:8090")
+//	records, err := userClient.GetAllRecords(ctx, "user_posts")
+func (r *ImpersonateResult) NewClient(baseURL string, opts ...Option) *Client {
+	client := NewClient(baseURL, opts...)
+	client.setAuth(r.Token, r.Record)
+	return client
+}
+
 // GetRecord fetches a single record from a collection by its ID.
 //
 // Example:
@@ -190,294 +571,1109 @@ func (c *Client) GetRecord(ctx context.Context, collection, recordID string, opt
 	for _, opt := range opts {
 		opt(options)
 	}
+	ctx = withRequestUserAgent(ctx, options.UserAgent)
 
-	endpoint := fmt.Sprintf("/api/collections/%s/records/%s", collection, recordID)
+	endpoint := fmt.Sprintf("/api/collections/%s/records/%s", url.PathEscape(collection), url.PathEscape(recordID))
 
 	// Build query parameters
-	params := url.Values{}
-	if len(options.Expand) > 0 {
-		params.Set("expand", strings.Join(options.Expand, ","))
-	}
-	if len(options.Fields) > 0 {
-		params.Set("fields", strings.Join(options.Fields, ","))
+	params, err := c.queryParams(options)
+	if err != nil {
+		return nil, err
 	}
 	if len(params) > 0 {
 		endpoint += "?" + params.Encode()
 	}
 
 	var record Record
-	err := c.doRequest(ctx, "GET", endpoint, nil, &record)
+	err = c.doRequest(ctx, "GET", endpoint, nil, &record)
 	if err != nil {
 		return nil, err
 	}
 
-	return record, nil
+	return c.applyRecordHook(record), nil
 }
 
-// GetAllRecords fetches all records from a collection, automatically handling pagination.
-// It continues fetching pages until all records are retrieved.
+// GetFirstListItem fetches the first record from a collection matching the given filter.
+// If no record matches, a 404 *APIError is returned.
 //
 // Example:
 //
-//	records, err := client.GetAllRecords(ctx, "posts")
+//	user, err := client.GetFirstListItem(ctx, "users", "email='alice@example.com'")
 //	if err != nil {
-//		// Handle error
 //		return err
 //	}
-//	fmt.Printf("Found %d posts", len(records))
-func (c *Client) GetAllRecords(ctx context.Context, collection string, opts ...ListOption) ([]Record, error) {
-	options := &ListOptions{
-		Page:    1,
-		PerPage: 30, // PocketBase default
-	}
+//	fmt.Printf("Found user: %s", user["id"])
+func (c *Client) GetFirstListItem(ctx context.Context, collection, filter string, opts ...QueryOption) (Record, error) {
+	options := &QueryOptions{}
 	for _, opt := range opts {
 		opt(options)
 	}
+	ctx = withRequestUserAgent(ctx, options.UserAgent)
 
-	var allRecords []Record
-	page := 1
-
-	// If a specific page was requested, fetch only that page
-	if options.Page > 1 {
-		page = options.Page
-		records, err := c.getRecordPage(ctx, collection, options, page)
-		if err != nil {
-			return nil, err
-		}
-		return records.Items, nil
-	}
-
-	// Fetch all pages
-	for {
-		options.Page = page
-		resp, err := c.getRecordPage(ctx, collection, options, page)
-		if err != nil {
-			return nil, err
-		}
-
-		allRecords = append(allRecords, resp.Items...)
-
-		// Check if we've reached the last page
-		if page >= resp.TotalPages {
-			break
-		}
-		page++
-	}
-
-	return allRecords, nil
-}
-
-// getRecordPage fetches a single page of records from a collection.
-func (c *Client) getRecordPage(ctx context.Context, collection string, options *ListOptions, page int) (*listResp, error) {
-	endpoint := fmt.Sprintf("/api/collections/%s/records", collection)
+	listOptions := &ListOptions{
+		PerPage: 1,
+		Filter:  filter,
+		Expand:  options.Expand,
+		Fields:  options.Fields,
 
-	// Build query parameters
-	params := url.Values{}
-	params.Set("page", strconv.Itoa(page))
-	if options.PerPage > 0 {
-		params.Set("perPage", strconv.Itoa(options.PerPage))
-	}
-	if options.Sort != "" {
-		params.Set("sort", options.Sort)
-	}
-	if options.Filter != "" {
-		params.Set("filter", options.Filter)
-	}
-	if len(options.Expand) > 0 {
-		params.Set("expand", strings.Join(options.Expand, ","))
+		FieldsPreset: options.FieldsPreset,
 	}
-	if len(options.Fields) > 0 {
-		params.Set("fields", strings.Join(options.Fields, ","))
-	}
-
-	endpoint += "?" + params.Encode()
 
-	var resp listResp
-	err := c.doRequest(ctx, "GET", endpoint, nil, &resp)
+	resp, err := c.getRecordPage(ctx, collection, listOptions, 1)
 	if err != nil {
 		return nil, err
 	}
 
-	return &resp, nil
+	if len(resp.Items) == 0 {
+		return nil, &APIError{
+			Status:  404,
+			Message: "The requested resource wasn't found.",
+		}
+	}
+
+	return resp.Items[0], nil
 }
 
-// CreateRecord creates a new record in the specified collection.
-// The record parameter should contain the field values for the new record.
-// Fields like 'id', 'created', and 'updated' are automatically generated by PocketBase.
+// GetRecordByField fetches the single record from a collection whose field equals value,
+// which is useful for looking up records by a unique slug or email instead of their ID.
+// The value is safely quoted and escaped in the generated filter.
+// If no record matches, a 404 *APIError is returned. If more than one record matches,
+// an error wrapping ErrMultipleRecords is returned.
 //
 // Example:
 //
-//	recordData := map[string]any{
-//		"title":   "My New Post",
-//		"content": "This is the content of my post",
-//		"status":  "draft",
-//		"author":  "user-id-123",
-//	}
-//
-//	createdRecord, err := client.CreateRecord(ctx, "posts", recordData)
+//	post, err := client.GetRecordByField(ctx, "posts", "slug", "hello-world")
 //	if err != nil {
 //		return err
 //	}
-//	fmt.Printf("Created record with ID: %s", createdRecord["id"])
-func (c *Client) CreateRecord(ctx context.Context, collection string, record Record, opts ...QueryOption) (Record, error) {
+//	fmt.Printf("Post title: %s", post["title"])
+func (c *Client) GetRecordByField(ctx context.Context, collection, field string, value any, opts ...QueryOption) (Record, error) {
+	if !fieldNamePattern.MatchString(field) {
+		return nil, fmt.Errorf("invalid field name %q", field)
+	}
+
 	options := &QueryOptions{}
 	for _, opt := range opts {
 		opt(options)
 	}
+	ctx = withRequestUserAgent(ctx, options.UserAgent)
 
-	endpoint := fmt.Sprintf("/api/collections/%s/records", collection)
+	listOptions := &ListOptions{
+		// Fetch two records to detect ambiguous matches
+		PerPage: 2,
+		Filter:  Filter(field+" = {:value}", map[string]any{"value": value}),
+		Expand:  options.Expand,
+		Fields:  options.Fields,
 
-	// Build query parameters
-	params := url.Values{}
-	if len(options.Expand) > 0 {
-		params.Set("expand", strings.Join(options.Expand, ","))
-	}
-	if len(options.Fields) > 0 {
-		params.Set("fields", strings.Join(options.Fields, ","))
-	}
-	if len(params) > 0 {
-		endpoint += "?" + params.Encode()
+		FieldsPreset: options.FieldsPreset,
 	}
 
-	var createdRecord Record
-	err := c.doRequest(ctx, "POST", endpoint, record, &createdRecord)
+	resp, err := c.getRecordPage(ctx, collection, listOptions, 1)
 	if err != nil {
 		return nil, err
 	}
 
-	return createdRecord, nil
+	switch len(resp.Items) {
+	case 0:
+		return nil, &APIError{
+			Status:  404,
+			Message: "The requested resource wasn't found.",
+		}
+	case 1:
+		return resp.Items[0], nil
+	default:
+		return nil, fmt.Errorf("%w: %s = %v", ErrMultipleRecords, field, value)
+	}
 }
 
-// UpdateRecord updates an existing record in the specified collection.
-// The record parameter should contain only the fields that need to be updated.
-// Fields like 'id', 'created', and 'updated' are automatically handled by PocketBase.
+// WaitForRecord polls a record with GetRecord every interval until cond returns true for
+// it, and returns that record. The first poll is made right away. It is meant for
+// workflows where a background job updates a record (e.g. flips a status field) and
+// realtime subscriptions aren't available. Bound the wait with a context deadline: when
+// ctx is done, ctx.Err() is returned. A failed poll, e.g. because the record doesn't
+// exist, stops the wait and returns the error.
 //
 // Example:
 //
-//	// Update a post's title and status
-//	updateData := map[string]any{
-//		"title":  "Updated Post Title",
-//		"status": "published",
-//	}
+//	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+//	defer cancel()
 //
-//	updatedRecord, err := client.UpdateRecord(ctx, "posts", "RECORD_ID_HERE", updateData)
+//	job, err := client.WaitForRecord(ctx, "jobs", jobID, func(job pocketbase.Record) bool {
+//		return job["status"] == "done" || job["status"] == "failed"
+//	}, 2*time.Second)
 //	if err != nil {
 //		return err
 //	}
-//	fmt.Printf("Updated record: %s", updatedRecord["title"])
-func (c *Client) UpdateRecord(ctx context.Context, collection, recordID string, record Record, opts ...QueryOption) (Record, error) {
-	options := &QueryOptions{}
-	for _, opt := range opts {
-		opt(options)
+//	fmt.Printf("Job finished with status %s", job["status"])
+func (c *Client) WaitForRecord(ctx context.Context, collection, id string, cond func(Record) bool, interval time.Duration) (Record, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("interval must be greater than zero, got %v", interval)
 	}
 
-	endpoint := fmt.Sprintf("/api/collections/%s/records/%s", collection, recordID)
+	for {
+		record, err := c.GetRecord(ctx, collection, id)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			return nil, err
+		}
+		if cond(record) {
+			return record, nil
+		}
 
-	// Build query parameters
-	params := url.Values{}
-	if len(options.Expand) > 0 {
-		params.Set("expand", strings.Join(options.Expand, ","))
-	}
-	if len(options.Fields) > 0 {
-		params.Set("fields", strings.Join(options.Fields, ","))
-	}
-	if len(params) > 0 {
-		endpoint += "?" + params.Encode()
+		if err := sleepContext(ctx, interval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// GetAllRecords fetches all records from a collection, automatically handling pagination.
+// It continues fetching pages until all records are retrieved.
+//
+// Example:
+//
+//	records, err := client.GetAllRecords(ctx, "posts")
+//	if err != nil {
+//		// Handle error
+//		return err
+//	}
+//	fmt.Printf("Found %d posts", len(records))
+func (c *Client) GetAllRecords(ctx context.Context, collection string, opts ...ListOption) ([]Record, error) {
+	options := c.newRecordListOptions(opts...)
+	ctx = withRequestUserAgent(ctx, options.UserAgent)
+
+	return c.getAllRecords(ctx, collection, options)
+}
+
+// GetRecordsSince fetches all records from a collection that were updated at or after
+// the given time, sorted by their "updated" field in ascending order.
+// It is intended for incremental synchronization, where only the records changed
+// since the last sync need to be fetched. A filter passed via WithFilter is combined
+// with the timestamp condition using "&&".
+//
+// Example:
+//
+//	records, err := client.GetRecordsSince(ctx, "posts", lastSync,
+//		pocketbase.WithFilter("status='published'"))
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Printf("%d posts changed since last sync", len(records))
+func (c *Client) GetRecordsSince(ctx context.Context, collection string, since time.Time, opts ...ListOption) ([]Record, error) {
+	options := c.newRecordListOptions(opts...)
+	ctx = withRequestUserAgent(ctx, options.UserAgent)
+
+	options.Filter = andFilters(options.Filter, fmt.Sprintf("updated >= '%s'", formatDateTime(since)))
+	options.Sort = "updated"
+
+	return c.getAllRecords(ctx, collection, options)
+}
+
+// GetRecords fetches up to limit records from a collection, paging through the results
+// until enough records are collected or no more records are available.
+// Only the pages needed to reach the limit are requested, and the per page size is
+// reduced to the limit when it is smaller. Sorting and filtering options are respected,
+// which makes it suitable for "top N" queries.
+//
+// Example:
+//
+//	latest, err := client.GetRecords(ctx, "posts", 10, pocketbase.WithSort("-created"))
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Printf("Fetched the %d latest posts", len(latest))
+func (c *Client) GetRecords(ctx context.Context, collection string, limit int, opts ...ListOption) ([]Record, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be greater than zero, got %d", limit)
+	}
+
+	options := c.newRecordListOptions(opts...)
+	ctx = withRequestUserAgent(ctx, options.UserAgent)
+
+	if options.PerPage <= 0 || options.PerPage > limit {
+		options.PerPage = limit
+	}
+
+	var records []Record
+	for page := 1; len(records) < limit; page++ {
+		options.Page = page
+		resp, err := c.getRecordPageWithRateLimit(ctx, collection, options, page)
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, resp.Items...)
+
+		// Check if we've reached the last page
+		if len(resp.Items) == 0 || isLastPage(resp, page) {
+			break
+		}
+	}
+
+	if len(records) > limit {
+		records = records[:limit]
+	}
+
+	return records, nil
+}
+
+// newListOptions creates list options with the client defaults and applies opts to them.
+func (c *Client) newListOptions(opts ...ListOption) *ListOptions {
+	options := &ListOptions{
+		Page:    1,
+		PerPage: c.defaultPerPage,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// newRecordListOptions creates list options for a record list request, applying the
+// options registered with WithDefaultListOptions before opts. A default filter is
+// combined with the per-call filter instead of being replaced by it.
+func (c *Client) newRecordListOptions(opts ...ListOption) *ListOptions {
+	options := c.newListOptions(c.defaultListOptions...)
+
+	defaultFilter := options.Filter
+	options.Filter = ""
+	for _, opt := range opts {
+		opt(options)
+	}
+	options.Filter = andFilters(defaultFilter, options.Filter)
+
+	return options
+}
+
+// getAllRecords fetches the records matching the given list options, following
+// pagination unless a specific page was requested.
+func (c *Client) getAllRecords(ctx context.Context, collection string, options *ListOptions) ([]Record, error) {
+	if c.recordHook == nil {
+		return c.getAllItems(ctx, recordsEndpoint(collection), options, 0)
+	}
+
+	hooked := *options
+	if callback := options.PageCallback; callback != nil {
+		hooked.PageCallback = func(items []Record) error {
+			return callback(c.applyRecordHooks(items))
+		}
+	}
+
+	records, err := c.getAllItems(ctx, recordsEndpoint(collection), &hooked, 0)
+	if err != nil {
+		return nil, err
+	}
+	return c.applyRecordHooks(records), nil
+}
+
+// applyRecordHook returns the record processed by the hook set with WithRecordHook.
+func (c *Client) applyRecordHook(record Record) Record {
+	if c.recordHook == nil || record == nil {
+		return record
+	}
+	return c.recordHook(record)
+}
+
+// applyRecordHooks applies the record hook to each of the records, in place.
+func (c *Client) applyRecordHooks(records []Record) []Record {
+	for i, record := range records {
+		records[i] = c.applyRecordHook(record)
+	}
+	return records
+}
+
+// getAllItems fetches the items of a paginated list endpoint matching the given list
+// options, following pagination unless a specific page was requested. When limit is
+// positive, pagination stops once at least limit items were fetched, and when more items
+// match, the first limit items are returned with an error wrapping ErrLimitReached.
+// When a page callback is set, each page is passed to it instead of being collected.
+func (c *Client) getAllItems(ctx context.Context, endpoint string, options *ListOptions, limit int) ([]Record, error) {
+	var allItems []Record
+	page := 1
+
+	// If a specific page was requested, fetch only that page
+	if options.Page > 1 {
+		page = options.Page
+		resp, err := c.getPage(ctx, endpoint, options, page)
+		if err != nil {
+			return nil, err
+		}
+		if options.PageCallback != nil {
+			return nil, options.PageCallback(resp.Items)
+		}
+		return resp.Items, nil
+	}
+
+	maxPages := options.MaxPages
+	if maxPages <= 0 {
+		maxPages = defaultMaxPages
+	}
+
+	// Fetch all pages
+	fetched := 0
+	for {
+		options.Page = page
+		resp, err := c.getPageWithRateLimit(ctx, endpoint, options, page)
+		if err != nil {
+			return nil, err
+		}
+
+		fetched += len(resp.Items)
+		if options.PageCallback != nil {
+			if err := options.PageCallback(resp.Items); err != nil {
+				return nil, err
+			}
+		} else {
+			allItems = append(allItems, resp.Items...)
+		}
+
+		// Check if we've reached the last page
+		lastPage := isLastPage(resp, page)
+		if limit > 0 && (fetched > limit || (fetched == limit && !lastPage)) {
+			if len(allItems) > limit {
+				allItems = allItems[:limit]
+			}
+			return allItems, fmt.Errorf("%w: stopped after %d items", ErrLimitReached, limit)
+		}
+		if lastPage {
+			break
+		}
+		if page >= maxPages {
+			// Without totals (WithSkipTotal) the server reports -1 pages
+			if resp.TotalPages < 0 {
+				return nil, fmt.Errorf("%w: stopped after %d pages", ErrMaxPagesExceeded, page)
+			}
+			return nil, fmt.Errorf("%w: stopped after %d pages of %d", ErrMaxPagesExceeded, page, resp.TotalPages)
+		}
+		page++
+	}
+
+	return allItems, nil
+}
+
+// isLastPage reports whether page is the last page of a list response. When the total
+// was skipped, the server reports -1 pages and the last page is the first one that isn't full.
+func isLastPage(resp *listResp, page int) bool {
+	if resp.TotalPages < 0 {
+		return len(resp.Items) == 0 || len(resp.Items) < resp.PerPage
+	}
+	return page >= resp.TotalPages
+}
+
+// getRecordPageWithRateLimit fetches a single page of records, waiting and retrying
+// when the server responds with 429 Too Many Requests so that a long pagination scan
+// isn't aborted by a rate limit.
+func (c *Client) getRecordPageWithRateLimit(ctx context.Context, collection string, options *ListOptions, page int) (*listResp, error) {
+	resp, err := c.getPageWithRateLimit(ctx, recordsEndpoint(collection), options, page)
+	if err != nil {
+		return nil, err
+	}
+	c.applyRecordHooks(resp.Items)
+	return resp, nil
+}
+
+// getPageWithRateLimit fetches a single page of a list endpoint, retrying on
+// 429 Too Many Requests like getRecordPageWithRateLimit.
+func (c *Client) getPageWithRateLimit(ctx context.Context, endpoint string, options *ListOptions, page int) (*listResp, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := c.getPage(ctx, endpoint, options, page)
+
+		var apiErr *APIError
+		if err == nil || !errors.As(err, &apiErr) || !apiErr.IsTooManyRequests() || attempt >= maxRateLimitRetries {
+			return resp, err
+		}
+
+		wait := apiErr.retryAfter
+		if wait <= 0 {
+			wait = defaultRateLimitWait
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// getRecordPage fetches a single page of records from a collection.
+func (c *Client) getRecordPage(ctx context.Context, collection string, options *ListOptions, page int) (*listResp, error) {
+	resp, err := c.getPage(ctx, recordsEndpoint(collection), options, page)
+	if err != nil {
+		return nil, err
+	}
+	c.applyRecordHooks(resp.Items)
+	return resp, nil
+}
+
+// getPage fetches a single page of a paginated list endpoint.
+func (c *Client) getPage(ctx context.Context, endpoint string, options *ListOptions, page int) (*listResp, error) {
+	params, err := c.listParams(options, page)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp listResp
+	err = c.doRequest(ctx, "GET", endpoint+"?"+params.Encode(), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// listParams builds the query parameters of a list request for the given page.
+func (c *Client) listParams(options *ListOptions, page int) (url.Values, error) {
+	params := url.Values{}
+	params.Set("page", strconv.Itoa(page))
+	if options.PerPage > 0 {
+		params.Set("perPage", strconv.Itoa(options.PerPage))
+	}
+	if options.Sort != "" {
+		params.Set("sort", options.Sort)
+	}
+	if options.SkipTotal {
+		params.Set("skipTotal", "1")
+	}
+	if options.Filter != "" {
+		params.Set("filter", options.Filter)
+	}
+	if len(options.Expand) > 0 {
+		params.Set("expand", strings.Join(options.Expand, ","))
+	}
+	fields, err := c.resolveFields(options.Fields, options.FieldsPreset)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) > 0 {
+		params.Set("fields", strings.Join(fields, ","))
+	}
+
+	return params, nil
+}
+
+// recordsEndpoint returns the records list endpoint of a collection.
+func recordsEndpoint(collection string) string {
+	return fmt.Sprintf("/api/collections/%s/records", url.PathEscape(collection))
+}
+
+// CreateRecord creates a new record in the specified collection.
+// The record parameter should contain the field values for the new record.
+// Fields like 'id', 'created', and 'updated' are automatically generated by PocketBase.
+//
+// Example:
+//
+//	recordData := map[string]any{
+//		"title":   "My New Post",
+//		"content": "This is the content of my post",
+//		"status":  "draft",
+//		"author":  "user-id-123",
+//	}
+//
+//	createdRecord, err := client.CreateRecord(ctx, "posts", recordData)
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Printf("Created record with ID: %s", createdRecord["id"])
+func (c *Client) CreateRecord(ctx context.Context, collection string, record Record, opts ...QueryOption) (Record, error) {
+	options := &QueryOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	ctx = withRequestUserAgent(ctx, options.UserAgent)
+
+	endpoint := fmt.Sprintf("/api/collections/%s/records", url.PathEscape(collection))
+
+	// Build query parameters
+	params, err := c.queryParams(options)
+	if err != nil {
+		return nil, err
+	}
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+
+	var createdRecord Record
+	err = c.doRequest(ctx, "POST", endpoint, record, &createdRecord)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.applyRecordHook(createdRecord), nil
+}
+
+// CreateRecordIfNotExists returns the first record matching uniqueFilter, creating it
+// from record when no match exists. The returned bool reports whether the record was created.
+// If another client creates a matching record between the lookup and the create, the
+// resulting unique constraint error is handled by fetching the existing record again.
+// This makes it suitable for idempotent seed and sync scripts.
+//
+// Example:
+//
+//	tag, created, err := client.CreateRecordIfNotExists(ctx, "tags", "slug='golang'",
+//		pocketbase.Record{"slug": "golang", "name": "Go"})
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Printf("Tag %s (created: %t)", tag["id"], created)
+func (c *Client) CreateRecordIfNotExists(ctx context.Context, collection, uniqueFilter string, record Record, opts ...QueryOption) (Record, bool, error) {
+	existing, err := c.GetFirstListItem(ctx, collection, uniqueFilter, opts...)
+	if err == nil {
+		return existing, false, nil
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !apiErr.IsNotFound() {
+		return nil, false, err
+	}
+
+	created, err := c.CreateRecord(ctx, collection, record, opts...)
+	if err == nil {
+		return created, true, nil
+	}
+
+	// The record may have been created concurrently after the lookup
+	if errors.As(err, &apiErr) && apiErr.IsBadRequest() && apiErr.hasFieldCode("validation_not_unique") {
+		existing, lookupErr := c.GetFirstListItem(ctx, collection, uniqueFilter, opts...)
+		if lookupErr == nil {
+			return existing, false, nil
+		}
+	}
+
+	return nil, false, err
+}
+
+// UpdateRecord updates an existing record in the specified collection.
+// The record parameter should contain only the fields that need to be updated.
+// Fields like 'id', 'created', and 'updated' are automatically handled by PocketBase.
+//
+// Example:
+//
+//	// Update a post's title and status
+//	updateData := map[string]any{
+//		"title":  "Updated Post Title",
+//		"status": "published",
+//	}
+//
+//	updatedRecord, err := client.UpdateRecord(ctx, "posts", "RECORD_ID_HERE", updateData)
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Printf("Updated record: %s", updatedRecord["title"])
+func (c *Client) UpdateRecord(ctx context.Context, collection, recordID string, record Record, opts ...QueryOption) (Record, error) {
+	options := &QueryOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	ctx = withRequestUserAgent(ctx, options.UserAgent)
+
+	endpoint := fmt.Sprintf("/api/collections/%s/records/%s", url.PathEscape(collection), url.PathEscape(recordID))
+
+	// Build query parameters
+	params, err := c.queryParams(options)
+	if err != nil {
+		return nil, err
+	}
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+
+	if options.ExpectedUpdated != "" {
+		if err := c.checkUpdated(ctx, collection, recordID, options.ExpectedUpdated); err != nil {
+			return nil, err
+		}
 	}
 
 	var updatedRecord Record
-	err := c.doRequest(ctx, "PATCH", endpoint, record, &updatedRecord)
+	err = c.doRequest(ctx, "PATCH", endpoint, record, &updatedRecord)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.applyRecordHook(updatedRecord), nil
+}
+
+// IncrementField atomically adds delta to a number field of a record and returns the
+// updated record. It sends the PocketBase number modifier {"field+": delta} (or
+// {"field-": -delta} for a negative delta), which is applied on the server, so concurrent
+// increments don't lose updates like a GetRecord and UpdateRecord round trip would.
+//
+// Example:
+//
+//	post, err := client.IncrementField(ctx, "posts", "RECORD_ID_HERE", "views", 1)
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Printf("Views: %v", post["views"])
+func (c *Client) IncrementField(ctx context.Context, collection, recordID, field string, delta float64, opts ...QueryOption) (Record, error) {
+	body := Record{field + "+": delta}
+	if delta < 0 {
+		body = Record{field + "-": -delta}
+	}
+
+	return c.UpdateRecord(ctx, collection, recordID, body, opts...)
+}
+
+// DeleteRecord deletes a record from the specified collection.
+// PocketBase answers a delete with 204 No Content, in which case the returned record is nil.
+// Servers that echo the deleted record (e.g. through a hook) answer with 200 and the record
+// body instead, which is decoded and returned, with the query options applied.
+//
+// Example:
+//
+//	deleted, err := client.DeleteRecord(ctx, "posts", "RECORD_ID_HERE")
+//	if err != nil {
+//		return err
+//	}
+//	if deleted != nil {
+//		fmt.Printf("Deleted post: %s", deleted["title"])
+//	}
+func (c *Client) DeleteRecord(ctx context.Context, collection, recordID string, opts ...QueryOption) (Record, error) {
+	options := &QueryOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	ctx = withRequestUserAgent(ctx, options.UserAgent)
+
+	endpoint := fmt.Sprintf("/api/collections/%s/records/%s", url.PathEscape(collection), url.PathEscape(recordID))
+
+	// Build query parameters
+	params, err := c.queryParams(options)
+	if err != nil {
+		return nil, err
+	}
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+
+	var deletedRecord Record
+	err = c.doRequest(ctx, "DELETE", endpoint, nil, &deletedRecord)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.applyRecordHook(deletedRecord), nil
+}
+
+// checkUpdated returns an error wrapping ErrUpdateConflict when the "updated" field of
+// a record differs from expected, meaning the record changed since it was read.
+func (c *Client) checkUpdated(ctx context.Context, collection, recordID, expected string) error {
+	current, err := c.GetRecord(ctx, collection, recordID, WithFields("updated"))
+	if err != nil {
+		return err
+	}
+
+	if updated, _ := current["updated"].(string); updated != expected {
+		return fmt.Errorf("%w: record %s was updated at %s, expected %s", ErrUpdateConflict, recordID, updated, expected)
+	}
+	return nil
+}
+
+// UpdateRecordsByFilter applies the same patch to every record in the collection matching
+// filter and returns the number of records updated. The IDs of the matching records are
+// collected before any update is made, so patches that change whether a record matches
+// the filter (e.g. archiving drafts) don't cause records to be skipped.
+//
+// By default it stops at the first failed update, returning the count of records updated
+// so far together with the error. Use WithContinueOnError to update the remaining records
+// anyway, in which case all failures are returned joined into a single error.
+// Query options passed with WithBulkQuery apply to each update request.
+//
+// Example:
+//
+//	cutoff := time.Now().AddDate(0, -6, 0)
+//	filter := pocketbase.Filter("status='draft' && updated < {:cutoff}", map[string]any{"cutoff": cutoff})
+//	updated, err := client.UpdateRecordsByFilter(ctx, "posts", filter, pocketbase.Record{"status": "archived"})
+//	if err != nil {
+//		return fmt.Errorf("archived %d drafts before failing: %w", updated, err)
+//	}
+func (c *Client) UpdateRecordsByFilter(ctx context.Context, collection, filter string, patch Record, opts ...BulkOption) (int, error) {
+	options := &BulkOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	ctx = withRequestUserAgent(ctx, options.UserAgent)
+
+	records, err := c.GetAllRecords(ctx, collection,
+		WithFilter(filter),
+		WithListFields("id"),
+		WithPerPage(maxPerPage))
+	if err != nil {
+		return 0, err
+	}
+
+	query := func(opts *QueryOptions) { *opts = options.QueryOptions }
+	updated := 0
+	var errs []error
+	for _, record := range records {
+		id, _ := record["id"].(string)
+		if _, err := c.UpdateRecord(ctx, collection, id, patch, query); err != nil {
+			err = fmt.Errorf("failed to update record %s: %w", id, err)
+			if !options.ContinueOnError || ctx.Err() != nil {
+				return updated, err
+			}
+			errs = append(errs, err)
+			continue
+		}
+		updated++
+	}
+
+	return updated, errors.Join(errs...)
+}
+
+// Send sends a request to an arbitrary PocketBase API endpoint, such as a custom route.
+// The path is relative to the client BaseURL and may include a query string.
+//
+// The body is encoded as JSON unless it is a *RawBody, []byte or io.Reader, in which case
+// it is sent as-is. Raw bodies use the RawBody content type, or "application/octet-stream"
+// when none is given. A successful JSON response is decoded into out if it is not nil.
+//
+// Example:
+//
+//	var result map[string]any
+//	err := client.Send(ctx, "POST", "/api/myapp/import",
+//		&pocketbase.RawBody{Reader: csvFile, ContentType: "text/csv"}, &result)
+func (c *Client) Send(ctx context.Context, method, path string, body, out any) error {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return c.doRequest(ctx, method, path, body, out)
+}
+
+// queryParams builds the expand and fields query parameters for single record requests.
+func (c *Client) queryParams(options *QueryOptions) (url.Values, error) {
+	params := url.Values{}
+	if len(options.Expand) > 0 {
+		params.Set("expand", strings.Join(options.Expand, ","))
+	}
+
+	fields, err := c.resolveFields(options.Fields, options.FieldsPreset)
 	if err != nil {
 		return nil, err
 	}
+	if options.MinimalResponse {
+		// PocketBase has no "Prefer: return=minimal" support, limit the response to the ID instead
+		fields = []string{"id"}
+	}
+	if len(fields) > 0 {
+		params.Set("fields", strings.Join(fields, ","))
+	}
+
+	return params, nil
+}
+
+// resolveFields expands the named fields preset, if any, and combines it with the explicit fields.
+func (c *Client) resolveFields(fields []string, preset string) ([]string, error) {
+	if preset == "" {
+		return fields, nil
+	}
+
+	presetFields, ok := c.fieldPresets[preset]
+	if !ok {
+		return nil, fmt.Errorf("unknown fields preset %q", preset)
+	}
 
-	return updatedRecord, nil
+	return append(presetFields[:len(presetFields):len(presetFields)], fields...), nil
 }
 
 // doRequest is a helper method that handles HTTP requests to the PocketBase API.
 // It manages request construction, authentication headers, JSON encoding/decoding,
-// and error handling.
+// and error handling. Requests go through the circuit breaker when one is configured.
 func (c *Client) doRequest(ctx context.Context, method, endpoint string, body any, out any) error {
+	if c.initErr != nil {
+		return c.initErr
+	}
+
+	ctx, stop, err := c.requestContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	if c.circuitBreaker == nil {
+		return canceledError(ctx, c.executeRequest(ctx, method, endpoint, body, out))
+	}
+
+	if err := c.circuitBreaker.allow(); err != nil {
+		return err
+	}
+
+	err = c.executeRequest(ctx, method, endpoint, body, out)
+	c.circuitBreaker.record(requestOutcome(err))
+
+	return canceledError(ctx, err)
+}
+
+// executeRequest sends a request and decodes the response, retrying transient
+// failures when enabled with WithRetry.
+func (c *Client) executeRequest(ctx context.Context, method, endpoint string, body any, out any) error {
 	// Check if this is a file upload request
 	if fileUploads, ok := body.(*FileUploadOptions); ok {
 		return c.doMultipartRequest(ctx, method, endpoint, fileUploads, out)
 	}
 
-	url := c.BaseURL + endpoint
+	url := c.baseURL() + endpoint
 
 	var reqBody []byte
 	var err error
-
-	// Encode request body as JSON if provided
-	if body != nil {
-		reqBody, err = json.Marshal(body)
+	contentType := c.contentType
+
+	switch b := body.(type) {
+	case nil:
+	case *RawBody:
+		// Raw bodies are sent as-is with their own content type
+		if b.Reader != nil {
+			reqBody, err = io.ReadAll(b.Reader)
+			if err != nil {
+				return fmt.Errorf("failed to read request body: %w", err)
+			}
+		}
+		contentType = b.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+	case []byte:
+		reqBody = b
+		contentType = "application/octet-stream"
+	case io.Reader:
+		reqBody, err = io.ReadAll(b)
+		if err != nil {
+			return fmt.Errorf("failed to read request body: %w", err)
+		}
+		contentType = "application/octet-stream"
+	default:
+		// Encode request body as JSON
+		reqBody, err = c.jsonMarshal(body)
 		if err != nil {
 			return fmt.Errorf("failed to marshal request body: %w", err)
 		}
 	}
 
-	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(reqBody))
+	// Execute request, retrying transient failures when enabled
+	var resp *http.Response
+	start := time.Now()
+	for attempt := 0; ; attempt++ {
+		resp, err = c.sendRequest(ctx, method, url, reqBody, contentType)
+		if !c.shouldRetry(ctx, method, attempt, resp, err) {
+			break
+		}
+		wait := c.retryBackoff(attempt + 1)
+		if c.retryBudget > 0 && time.Since(start)+wait >= c.retryBudget {
+			// Another attempt would exceed the retry budget, report the last failure
+			break
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Handle non-2xx responses
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newAPIError(resp)
+	}
+
+	// Decode successful response
+	return c.decodeResponse(resp, out)
+}
+
+// sendRequest sends a single request attempt. The body is kept as a byte slice so each
+// attempt gets a fresh reader, and GetBody lets the transport replay it on redirects.
+func (c *Client) sendRequest(ctx context.Context, method, url string, reqBody []byte, contentType string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(c.traceContext(ctx), method, url, bytes.NewReader(reqBody))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(reqBody)), nil
 	}
 
 	// Set headers
-	req.Header.Set("Content-Type", "application/json")
+	c.setRequestHeaders(ctx, req)
+	req.Header.Set("Content-Type", contentType)
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("User-Agent", c.userAgent)
 
 	// Add authorization header if token is available
-	if token := c.GetToken(); token != "" {
-		req.Header.Set("Authorization", token)
+	if err := c.setAuthHeader(req); err != nil {
+		return nil, err
 	}
 
-	// Execute request
-	resp, err := c.HTTPClient.Do(req)
+	if c.bodyLog != nil {
+		c.bodyLog.logRequest(req, reqBody)
+	}
+
+	resp, err := c.httpClient().Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to execute request: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	// Handle non-2xx responses
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		var apiErr apiErrorResp
-		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil {
-			// If we can't decode the error response, create a generic API error
-			return &APIError{
-				Status:  resp.StatusCode,
-				Message: resp.Status,
-				Data:    nil,
-			}
-		}
-		return &APIError{
-			Status:  apiErr.Status,
-			Message: apiErr.Message,
-			Data:    apiErr.Data,
-		}
+	c.checkRedirect(req, resp)
+	c.observeRateLimit(resp)
+
+	if c.bodyLog != nil {
+		c.bodyLog.logResponse(req, resp)
 	}
 
-	// Decode successful response
-	if out != nil {
-		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
-			return fmt.Errorf("failed to decode response: %w", err)
+	return resp, nil
+}
+
+// shouldRetry reports whether a failed request attempt should be retried. Only idempotent
+// methods and requests marked with withRetryable are retried, on network errors and 502,
+// 503 and 504 responses, up to the number of retries configured with WithRetry.
+func (c *Client) shouldRetry(ctx context.Context, method string, attempt int, resp *http.Response, err error) bool {
+	if attempt >= c.maxRetries || ctx.Err() != nil || !(isIdempotent(method) || isRetryable(ctx)) {
+		return false
+	}
+	if err != nil {
+		// The request was refused before being sent, a retry would fail the same way
+		return !errors.Is(err, ErrInsecureAuth)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// isIdempotent reports whether repeating a request with the given method has the same
+// effect as sending it once.
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	default:
+		return false
+	}
+}
+
+// retryableKey is the context key marking a non-idempotent request as safe to retry.
+type retryableKey struct{}
+
+// withRetryable marks the requests made with ctx as safe to retry even though their
+// method isn't idempotent, e.g. password authentication which has no side effects.
+func withRetryable(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryableKey{}, true)
+}
+
+// isRetryable reports whether ctx was marked with withRetryable.
+func isRetryable(ctx context.Context) bool {
+	retryable, _ := ctx.Value(retryableKey{}).(bool)
+	return retryable
+}
+
+// retryBackoff returns the wait before the given retry, starting at 1 for the first
+// retry, using the backoff function configured with WithBackoff or DefaultBackoff.
+func (c *Client) retryBackoff(retry int) time.Duration {
+	if c.backoff != nil {
+		return c.backoff(retry)
+	}
+	return DefaultBackoff(retry)
+}
+
+// DefaultBackoff is the backoff used between retries when no custom one is set with
+// WithBackoff. It uses exponential backoff with full jitter: the wait before a retry is
+// random between zero and a cap that doubles with each retry, starting at 100ms and
+// limited to 5s. The randomness spreads out the retries of many clients after an outage
+// instead of having them all hit the server at the same time.
+func DefaultBackoff(retry int) time.Duration {
+	// Stop doubling once the cap is reached, so large retry counts can't overflow
+	ceiling := minRetryBackoff
+	for i := 1; i < retry && ceiling < maxRetryBackoff; i++ {
+		ceiling <<= 1
+	}
+	return rand.N(min(ceiling, maxRetryBackoff))
+}
+
+// sleepContext waits for the given duration or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// quoteEscaper escapes the quoted parameters of a Content-Disposition header.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// formFileHeader returns the part header of a file upload, like multipart.Writer.CreateFormFile.
+// A non-ASCII filename is sent percent-encoded in the RFC 5987 filename* parameter, which
+// takes precedence over filename, with an ASCII fallback in filename for older parsers.
+func formFileHeader(field, filename string) textproto.MIMEHeader {
+	disposition := fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(field), quoteEscaper.Replace(asciiFilename(filename)))
+	if !isASCII(filename) {
+		disposition += "; filename*=UTF-8''" + encodeRFC5987(filename)
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", disposition)
+	header.Set("Content-Type", "application/octet-stream")
+	return header
+}
+
+// isASCII reports whether s only contains printable ASCII characters.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return false
 		}
 	}
+	return true
+}
 
-	return nil
+// asciiFilename replaces the characters of a filename that aren't printable ASCII with "_".
+func asciiFilename(filename string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e {
+			return '_'
+		}
+		return r
+	}, filename)
+}
+
+// encodeRFC5987 percent-encodes a value for an RFC 5987 extended parameter, leaving
+// only the attr-char characters as is.
+func encodeRFC5987(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		ch := value[i]
+		if ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z') || ('0' <= ch && ch <= '9') ||
+			strings.IndexByte("!#$&+-.^_`|~", ch) >= 0 {
+			b.WriteByte(ch)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", ch)
+	}
+	return b.String()
 }
 
 // doMultipartRequest handles multipart/form-data requests for file uploads
 func (c *Client) doMultipartRequest(ctx context.Context, method, endpoint string, fileUploads *FileUploadOptions, out any) error {
-	fullURL := c.BaseURL + endpoint
+	fullURL := c.baseURL() + endpoint
+
+	ctx = withRequestUserAgent(ctx, fileUploads.UserAgent)
 
 	// Parse query parameters from options
-	params := url.Values{}
-	if len(fileUploads.Expand) > 0 {
-		params.Set("expand", strings.Join(fileUploads.Expand, ","))
-	}
-	if len(fileUploads.Fields) > 0 {
-		params.Set("fields", strings.Join(fileUploads.Fields, ","))
+	params, err := c.queryParams(&fileUploads.QueryOptions)
+	if err != nil {
+		return err
 	}
 	if len(params) > 0 {
 		fullURL += "?" + params.Encode()
@@ -499,7 +1695,7 @@ func (c *Client) doMultipartRequest(ctx context.Context, method, endpoint string
 				strValue = fmt.Sprintf("%v", v)
 			default:
 				// For complex types, marshal to JSON
-				jsonBytes, err := json.Marshal(v)
+				jsonBytes, err := c.jsonMarshal(v)
 				if err != nil {
 					return fmt.Errorf("failed to marshal form field %s: %w", key, err)
 				}
@@ -532,7 +1728,7 @@ func (c *Client) doMultipartRequest(ctx context.Context, method, endpoint string
 
 		// Add files
 		for _, file := range upload.Files {
-			part, err := writer.CreateFormFile(fieldName, file.Filename)
+			part, err := writer.CreatePart(formFileHeader(fieldName, file.Filename))
 			if err != nil {
 				return fmt.Errorf("failed to create form file: %w", err)
 			}
@@ -544,58 +1740,222 @@ func (c *Client) doMultipartRequest(ctx context.Context, method, endpoint string
 		}
 	}
 
-	err := writer.Close()
+	err = writer.Close()
 	if err != nil {
 		return fmt.Errorf("failed to close multipart writer: %w", err)
 	}
 
 	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, method, fullURL, &reqBody)
+	req, err := http.NewRequestWithContext(c.traceContext(ctx), method, fullURL, &reqBody)
 	if err != nil {
 		return fmt.Errorf("failed to create multipart request: %w", err)
 	}
 
 	// Set headers
+	c.setRequestHeaders(ctx, req)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("User-Agent", c.userAgent)
 
 	// Add authorization header if token is available
-	if token := c.GetToken(); token != "" {
-		req.Header.Set("Authorization", token)
+	if err := c.setAuthHeader(req); err != nil {
+		return err
+	}
+
+	if c.bodyLog != nil {
+		c.bodyLog.logRequest(req, reqBody.Bytes())
 	}
 
 	// Execute request
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.uploadHTTPClient(ctx).Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to execute multipart request: %w", err)
 	}
 	defer resp.Body.Close()
 
+	c.checkRedirect(req, resp)
+	c.observeRateLimit(resp)
+
+	if c.bodyLog != nil {
+		c.bodyLog.logResponse(req, resp)
+	}
+
 	// Handle non-2xx responses
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		var apiErr apiErrorResp
-		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil {
-			// If we can't decode the error response, create a generic API error
-			return &APIError{
-				Status:  resp.StatusCode,
-				Message: resp.Status,
-				Data:    nil,
-			}
-		}
-		return &APIError{
-			Status:  apiErr.Status,
-			Message: apiErr.Message,
-			Data:    apiErr.Data,
-		}
+		return newAPIError(resp)
 	}
 
 	// Decode successful response
-	if out != nil {
-		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+	return c.decodeResponse(resp, out)
+}
+
+// decodeResponse decodes a successful response body into out using the configured JSON unmarshaler.
+func (c *Client) decodeResponse(resp *http.Response, out any) error {
+	if out == nil {
+		return nil
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	// Responses like 204 No Content have nothing to decode
+	if len(data) == 0 {
+		return nil
+	}
+
+	if err := c.jsonUnmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// decodeTyped decodes a JSON value into a user-defined type for the generic helpers.
+// In strict mode, fields of the value that T doesn't declare are reported as errors.
+func (c *Client) decodeTyped(data []byte, out any) error {
+	if !c.strictDecoding {
+		if err := c.jsonUnmarshal(data, out); err != nil {
 			return fmt.Errorf("failed to decode response: %w", err)
 		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// requestUserAgentKey is the context key of a per-request User-Agent override.
+type requestUserAgentKey struct{}
+
+// withRequestUserAgent returns a context carrying a User-Agent override for the requests
+// made with it. An empty userAgent leaves ctx unchanged.
+func withRequestUserAgent(ctx context.Context, userAgent string) context.Context {
+	if userAgent == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestUserAgentKey{}, userAgent)
+}
+
+// requestUserAgent returns the User-Agent for a request, preferring a per-request
+// override set with WithRequestUserAgent over the client default.
+func (c *Client) requestUserAgent(ctx context.Context) string {
+	if userAgent, ok := ctx.Value(requestUserAgentKey{}).(string); ok {
+		return userAgent
+	}
+	return c.userAgent
+}
+
+// requestTokenKey is the context key of a per-request authentication token.
+type requestTokenKey struct{}
+
+// ContextWithToken returns a context carrying an authentication token used for the
+// requests made with it instead of the token stored in the client. This lets a single
+// shared client make requests on behalf of different users, e.g. in a server forwarding
+// the token of each incoming request, without mutating the client.
+//
+// Example:
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//		ctx := pocketbase.ContextWithToken(r.Context(), r.Header.Get("Authorization"))
+//		posts, err := client.GetAllRecords(ctx, "posts")
+//		// ...
+//	}
+func ContextWithToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, requestTokenKey{}, token)
+}
+
+// requestToken returns the authentication token for a request, preferring a token set
+// with ContextWithToken over the token stored in the client.
+func (c *Client) requestToken(ctx context.Context) string {
+	if token, ok := ctx.Value(requestTokenKey{}).(string); ok {
+		return token
+	}
+	return c.GetToken()
+}
+
+// traceContext attaches a fresh client trace to ctx when WithClientTrace is configured.
+func (c *Client) traceContext(ctx context.Context) context.Context {
+	if c.clientTrace == nil {
+		return ctx
+	}
+	if trace := c.clientTrace(); trace != nil {
+		return httptrace.WithClientTrace(ctx, trace)
+	}
+	return ctx
+}
+
+// setAuthHeader adds the authentication token to a request when one is set, preferring
+// a token attached to the request context with ContextWithToken. With
+// WithRequireHTTPSForAuth, it returns ErrInsecureAuth instead of adding the token to
+// a plain HTTP request, unless the request goes to localhost.
+func (c *Client) setAuthHeader(req *http.Request) error {
+	token := c.requestToken(req.Context())
+	if token == "" {
+		return nil
+	}
+
+	if c.requireHTTPSForAuth && req.URL.Scheme != "https" && !isLocalhost(req.URL.Hostname()) {
+		return fmt.Errorf("%w: %s", ErrInsecureAuth, req.URL.Redacted())
 	}
 
+	req.Header.Set(c.tokenHeader, token)
 	return nil
 }
+
+// isLocalhost reports whether host refers to the local machine.
+func isLocalhost(host string) bool {
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// httpClient returns the HTTP client used to execute requests, applying the configured redirect policy.
+func (c *Client) httpClient() *http.Client {
+	if c.redirectPolicy == nil {
+		return c.HTTPClient
+	}
+
+	httpClient := *c.HTTPClient
+	httpClient.CheckRedirect = c.redirectPolicy
+	return &httpClient
+}
+
+// uploadHTTPClient returns the HTTP client used for file uploads. The HTTP client timeout
+// caps the whole request including the upload body, so a large upload can be cut short even
+// though the caller allowed more time. When ctx has a deadline later than the client timeout,
+// the timeout is dropped for the request and the context deadline bounds it instead.
+func (c *Client) uploadHTTPClient(ctx context.Context) *http.Client {
+	httpClient := c.httpClient()
+
+	deadline, ok := ctx.Deadline()
+	if !ok || httpClient.Timeout <= 0 || time.Until(deadline) <= httpClient.Timeout {
+		return httpClient
+	}
+
+	withoutTimeout := *httpClient
+	withoutTimeout.Timeout = 0
+	return &withoutTimeout
+}
+
+// checkRedirect logs a warning when an authenticated request was redirected and the
+// authentication header was dropped along the way, which Go's HTTP client does for
+// redirects to a different host. Such requests usually fail with a confusing 401.
+func (c *Client) checkRedirect(req *http.Request, resp *http.Response) {
+	if resp.Request == nil || resp.Request == req || req.Header.Get(c.tokenHeader) == "" {
+		return
+	}
+
+	if resp.Request.Header.Get(c.tokenHeader) == "" {
+		c.logger.Warn("pocketbase: authentication header was dropped on redirect",
+			"from", req.URL.Redacted(),
+			"to", resp.Request.URL.Redacted(),
+			"header", c.tokenHeader)
+	}
+}
@@ -2,10 +2,25 @@ package pocketbase
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/http/httptrace"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -59,6 +74,35 @@ func TestClient_SetToken(t *testing.T) {
 	}
 }
 
+func TestContextWithToken(t *testing.T) {
+	var authHeaders []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"abc"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.SetToken("client-token")
+
+	ctx := ContextWithToken(context.Background(), "context-token")
+	if _, err := client.GetRecord(ctx, "posts", "abc"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := client.GetRecord(context.Background(), "posts", "abc"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{"context-token", "client-token"}
+	if !slices.Equal(authHeaders, expected) {
+		t.Errorf("Expected Authorization headers %v, got %v", expected, authHeaders)
+	}
+	if client.GetToken() != "client-token" {
+		t.Errorf("Expected client token to be unchanged, got '%s'", client.GetToken())
+	}
+}
+
 func TestClient_AuthenticateWithPassword_Success(t *testing.T) {
 	// Mock server that returns successful authentication
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -355,8 +399,9 @@ func TestClient_Impersonate_WithOptions(t *testing.T) {
 	client.SetToken("superuser-token")
 
 	result, err := client.Impersonate(context.Background(), "users", "user-id-789", 0,
-		WithExpand("profile", "settings"),
-		WithFields("id", "email", "username"))
+		WithImpersonateQuery(
+			WithExpand("profile", "settings"),
+			WithFields("id", "email", "username")))
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -366,6 +411,41 @@ func TestClient_Impersonate_WithOptions(t *testing.T) {
 	}
 }
 
+func TestClient_Impersonate_AuditMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		if body["reason"] != "support ticket #1234" {
+			t.Errorf("Expected reason in request body, got %v", body["reason"])
+		}
+		if body["ticket"] != float64(1234) {
+			t.Errorf("Expected ticket in request body, got %v", body["ticket"])
+		}
+		if body["duration"] != float64(3600) {
+			t.Errorf("Expected duration 3600, got %v", body["duration"])
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(impersonateResp{Token: "impersonated", Record: Record{"id": "user-1"}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.SetToken(testToken(map[string]any{"id": "superuser-1", "type": "auth"}))
+
+	result, err := client.Impersonate(context.Background(), "users", "user-1", 3600,
+		WithImpersonationReason("support ticket #1234"),
+		WithImpersonationMetadata("ticket", 1234))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.ImpersonatorID != "superuser-1" {
+		t.Errorf("Expected impersonator ID 'superuser-1', got '%s'", result.ImpersonatorID)
+	}
+}
+
 func TestClient_GetRecord_Success(t *testing.T) {
 	// Mock server that returns a single record
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -726,6 +806,46 @@ func TestWithTimeout(t *testing.T) {
 	}
 }
 
+func TestWithTimeout_SharedHTTPClient(t *testing.T) {
+	transport := &http.Transport{}
+	shared := &http.Client{Transport: transport, Timeout: time.Minute}
+
+	for name, opts := range map[string][]Option{
+		"timeout after client":  {WithHTTPClient(shared), WithTimeout(5 * time.Second)},
+		"timeout before client": {WithTimeout(5 * time.Second), WithHTTPClient(shared)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			client := NewClient("http://localhost:8090", opts...)
+
+			if shared.Timeout != time.Minute {
+				t.Errorf("Expected the shared client to be unchanged, got timeout %v", shared.Timeout)
+			}
+			if client.HTTPClient == shared {
+				t.Error("Expected the client to use a copy of the shared client")
+			}
+			if client.HTTPClient.Timeout != 5*time.Second {
+				t.Errorf("Expected timeout 5s, got %v", client.HTTPClient.Timeout)
+			}
+			if client.HTTPClient.Transport != transport {
+				t.Error("Expected the shared transport to be kept")
+			}
+		})
+	}
+
+	t.Run("clone", func(t *testing.T) {
+		client := NewClient("http://localhost:8090", WithHTTPClientClone(shared))
+		shared.Timeout = time.Hour
+		defer func() { shared.Timeout = time.Minute }()
+
+		if client.HTTPClient.Timeout != time.Minute {
+			t.Errorf("Expected later changes to the shared client to be ignored, got %v", client.HTTPClient.Timeout)
+		}
+		if client.HTTPClient.Transport == transport {
+			t.Error("Expected the transport to be cloned")
+		}
+	})
+}
+
 func TestGetAllRecords_WithListOptions(t *testing.T) {
 	// Mock server that verifies query parameters for list options
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1296,3 +1416,5087 @@ func TestClient_UpdateRecord_Unauthorized(t *testing.T) {
 		t.Error("Expected IsUnauthorized() to return true")
 	}
 }
+
+func TestFormatDateTime(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	since := time.Date(2024, 3, 15, 14, 30, 45, 123000000, loc)
+
+	formatted := formatDateTime(since)
+
+	expected := "2024-03-15 12:30:45.123Z"
+	if formatted != expected {
+		t.Errorf("Expected formatted datetime '%s', got '%s'", expected, formatted)
+	}
+}
+
+func TestClient_GetRecordsSince(t *testing.T) {
+	since := time.Date(2024, 3, 15, 12, 30, 45, 0, time.UTC)
+
+	t.Run("filters and sorts by updated", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+
+			expectedFilter := "updated >= '2024-03-15 12:30:45.000Z'"
+			if query.Get("filter") != expectedFilter {
+				t.Errorf("Expected filter '%s', got '%s'", expectedFilter, query.Get("filter"))
+			}
+			if query.Get("sort") != "updated" {
+				t.Errorf("Expected sort 'updated', got '%s'", query.Get("sort"))
+			}
+
+			response := listResp{
+				Page:       1,
+				PerPage:    30,
+				TotalItems: 1,
+				TotalPages: 1,
+				Items:      []Record{{"id": "record-1"}},
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(response)
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+
+		records, err := client.GetRecordsSince(context.Background(), "posts", since)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(records) != 1 {
+			t.Errorf("Expected 1 record, got %d", len(records))
+		}
+	})
+
+	t.Run("combines with additional filter", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			expectedFilter := "(status='published' || featured=true) && (updated >= '2024-03-15 12:30:45.000Z')"
+			if filter := r.URL.Query().Get("filter"); filter != expectedFilter {
+				t.Errorf("Expected filter '%s', got '%s'", expectedFilter, filter)
+			}
+
+			response := listResp{Page: 1, PerPage: 30, TotalPages: 1}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(response)
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+
+		_, err := client.GetRecordsSince(context.Background(), "posts", since,
+			WithFilter("status='published' || featured=true"))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	})
+}
+
+func TestWithTokenHeaderName(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "test-token" {
+			t.Errorf("Expected X-Token header to be 'test-token', got '%s'", r.Header.Get("X-Token"))
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("Expected no Authorization header, got '%s'", r.Header.Get("Authorization"))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Record{"id": "record-1"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithTokenHeaderName("X-Token"))
+	client.SetToken("test-token")
+
+	_, err := client.GetRecord(context.Background(), "posts", "record-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	_, err = client.CreateRecordWithFiles(context.Background(), "posts",
+		WithFileUpload("file", []FileData{CreateFileDataFromBytes([]byte("data"), "file.txt")}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestWithUserAgentSuffix(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expected := "pocketbase-go/1.0 MyApp/2.3"
+		if r.Header.Get("User-Agent") != expected {
+			t.Errorf("Expected User-Agent '%s', got '%s'", expected, r.Header.Get("User-Agent"))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Record{"id": "record-1"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithUserAgentSuffix("MyApp/2.3"))
+
+	_, err := client.GetRecord(context.Background(), "posts", "record-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestClient_PathSegmentsAreEscaped(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expectedPath := "/api/collections/my%20posts/records/a%2Fb%3Fc"
+		if r.URL.EscapedPath() != expectedPath {
+			t.Errorf("Expected escaped path '%s', got '%s'", expectedPath, r.URL.EscapedPath())
+		}
+		if r.URL.RawQuery != "" {
+			t.Errorf("Expected no query string, got '%s'", r.URL.RawQuery)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Record{"id": "a/b?c"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	record, err := client.GetRecord(context.Background(), "my posts", "a/b?c")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if record["id"] != "a/b?c" {
+		t.Errorf("Expected record ID 'a/b?c', got '%v'", record["id"])
+	}
+
+	_, err = client.UpdateRecord(context.Background(), "my posts", "a/b?c", Record{"title": "Updated"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestClient_GetAllRecords_RateLimitedPage(t *testing.T) {
+	// Mock server that rate limits the second page once
+	rateLimited := false
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		page := r.URL.Query().Get("page")
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if page == "2" && !rateLimited {
+			rateLimited = true
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(apiErrorResp{Status: 429, Message: "Too Many Requests."})
+			return
+		}
+
+		response := listResp{
+			Page:       1,
+			PerPage:    1,
+			TotalItems: 2,
+			TotalPages: 2,
+			Items:      []Record{{"id": "record-" + page}},
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	records, err := client.GetAllRecords(context.Background(), "posts", WithPerPage(1))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(records) != 2 {
+		t.Errorf("Expected 2 records, got %d", len(records))
+	}
+	if requestCount != 3 {
+		t.Errorf("Expected 3 requests to be made, got %d", requestCount)
+	}
+}
+
+func TestClient_GetAllRecords_RateLimitWaitCanceled(t *testing.T) {
+	// Mock server that always rate limits with a long Retry-After
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(apiErrorResp{Status: 429, Message: "Too Many Requests."})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.GetAllRecords(ctx, "posts")
+	if err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestWithJSONMarshaler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		if body["title"] != "HELLO" {
+			t.Errorf("Expected transformed title 'HELLO', got '%v'", body["title"])
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Record{"id": "record-1", "title": "HELLO"})
+	}))
+	defer server.Close()
+
+	// Custom marshaler that upper-cases the title field
+	marshaler := func(v any) ([]byte, error) {
+		if record, ok := v.(Record); ok {
+			record["title"] = strings.ToUpper(record["title"].(string))
+		}
+		return json.Marshal(v)
+	}
+
+	// Custom unmarshaler that marks every decoded record
+	unmarshaler := func(data []byte, v any) error {
+		if err := json.Unmarshal(data, v); err != nil {
+			return err
+		}
+		if record, ok := v.(*Record); ok {
+			(*record)["decoded"] = true
+		}
+		return nil
+	}
+
+	client := NewClient(server.URL, WithJSONMarshaler(marshaler), WithJSONUnmarshaler(unmarshaler))
+
+	record, err := client.CreateRecord(context.Background(), "posts", Record{"title": "hello"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if record["decoded"] != true {
+		t.Error("Expected custom unmarshaler to be used for the response")
+	}
+}
+
+func TestWithContentType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			// File uploads keep their multipart content type
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(Record{"id": "record-2"})
+			return
+		}
+
+		expected := "application/json; charset=utf-8"
+		if r.Header.Get("Content-Type") != expected {
+			t.Errorf("Expected Content-Type '%s', got '%s'", expected, r.Header.Get("Content-Type"))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Record{"id": "record-1"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithContentType("application/json; charset=utf-8"))
+
+	_, err := client.CreateRecord(context.Background(), "posts", Record{"title": "Post"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	record, err := client.CreateRecordWithFiles(context.Background(), "posts",
+		WithFileUpload("file", []FileData{CreateFileDataFromBytes([]byte("data"), "file.txt")}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if record["id"] != "record-2" {
+		t.Errorf("Expected multipart request to keep its content type, got record '%v'", record["id"])
+	}
+}
+
+func TestClient_CreateRecordWithFiles_UnicodeFilename(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			t.Fatalf("Expected a multipart body, got %v", err)
+		}
+
+		var names []string
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Failed to read part: %v", err)
+			}
+			names = append(names, part.FileName())
+
+			disposition := part.Header.Get("Content-Disposition")
+			if part.FileName() == "résumé.pdf" && disposition != `form-data; name="documents"; filename="r_sum_.pdf"; filename*=UTF-8''r%C3%A9sum%C3%A9.pdf` {
+				t.Errorf("Expected an RFC 5987 encoded filename, got '%s'", disposition)
+			}
+			if part.FileName() == "notes.txt" && strings.Contains(disposition, "filename*") {
+				t.Errorf("Expected ASCII filenames to be sent as is, got '%s'", disposition)
+			}
+		}
+
+		if !slices.Equal(names, []string{"résumé.pdf", "notes.txt"}) {
+			t.Errorf("Expected the server to decode the filenames, got %v", names)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Record{"id": "record-1"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	_, err := client.CreateRecordWithFiles(context.Background(), "documents",
+		WithFileUpload("documents", []FileData{
+			CreateFileDataFromBytes([]byte("%PDF"), "résumé.pdf"),
+			CreateFileDataFromBytes([]byte("notes"), "notes.txt"),
+		}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestClient_GetRecords(t *testing.T) {
+	t.Run("stops once the limit is reached", func(t *testing.T) {
+		requestCount := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestCount++
+			query := r.URL.Query()
+
+			if query.Get("perPage") != "2" {
+				t.Errorf("Expected perPage parameter '2', got '%s'", query.Get("perPage"))
+			}
+			if query.Get("sort") != "-created" {
+				t.Errorf("Expected sort parameter '-created', got '%s'", query.Get("sort"))
+			}
+
+			page := query.Get("page")
+			response := listResp{
+				PerPage:    2,
+				TotalItems: 10,
+				TotalPages: 5,
+				Items: []Record{
+					{"id": "record-" + page + "a"},
+					{"id": "record-" + page + "b"},
+				},
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(response)
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+
+		records, err := client.GetRecords(context.Background(), "posts", 3, WithSort("-created"), WithPerPage(2))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(records) != 3 {
+			t.Errorf("Expected 3 records, got %d", len(records))
+		}
+		if requestCount != 2 {
+			t.Errorf("Expected 2 requests to be made, got %d", requestCount)
+		}
+		if records[2]["id"] != "record-2a" {
+			t.Errorf("Expected last record ID 'record-2a', got '%v'", records[2]["id"])
+		}
+	})
+
+	t.Run("reduces page size to the limit", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if perPage := r.URL.Query().Get("perPage"); perPage != "5" {
+				t.Errorf("Expected perPage parameter '5', got '%s'", perPage)
+			}
+
+			response := listResp{
+				PerPage:    5,
+				TotalItems: 2,
+				TotalPages: 1,
+				Items:      []Record{{"id": "record-1"}, {"id": "record-2"}},
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(response)
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+
+		records, err := client.GetRecords(context.Background(), "posts", 5)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(records) != 2 {
+			t.Errorf("Expected 2 records, got %d", len(records))
+		}
+	})
+
+	t.Run("rejects non-positive limit", func(t *testing.T) {
+		client := NewClient("http://localhost:8090")
+
+		if _, err := client.GetRecords(context.Background(), "posts", 0); err == nil {
+			t.Error("Expected error for zero limit")
+		}
+	})
+}
+
+func TestClient_GetFirstListItem(t *testing.T) {
+	t.Run("returns first match", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			if query.Get("filter") != "slug='hello'" {
+				t.Errorf("Expected filter \"slug='hello'\", got '%s'", query.Get("filter"))
+			}
+			if query.Get("perPage") != "1" {
+				t.Errorf("Expected perPage parameter '1', got '%s'", query.Get("perPage"))
+			}
+
+			response := listResp{Page: 1, PerPage: 1, TotalItems: 1, TotalPages: 1, Items: []Record{{"id": "record-1"}}}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(response)
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+
+		record, err := client.GetFirstListItem(context.Background(), "posts", "slug='hello'")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if record["id"] != "record-1" {
+			t.Errorf("Expected record ID 'record-1', got '%v'", record["id"])
+		}
+	})
+
+	t.Run("returns not found without matches", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(listResp{Page: 1, PerPage: 1})
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+
+		_, err := client.GetFirstListItem(context.Background(), "posts", "slug='missing'")
+
+		apiErr, ok := err.(*APIError)
+		if !ok {
+			t.Fatalf("Expected APIError, got %T", err)
+		}
+		if !apiErr.IsNotFound() {
+			t.Error("Expected IsNotFound() to return true")
+		}
+	})
+}
+
+func TestClient_CreateRecordIfNotExists(t *testing.T) {
+	t.Run("returns existing record", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != "GET" {
+				t.Errorf("Expected only GET requests, got %s", r.Method)
+			}
+
+			response := listResp{Page: 1, PerPage: 1, TotalItems: 1, TotalPages: 1, Items: []Record{{"id": "existing"}}}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(response)
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+
+		record, created, err := client.CreateRecordIfNotExists(context.Background(), "tags", "slug='go'", Record{"slug": "go"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if created {
+			t.Error("Expected created to be false")
+		}
+		if record["id"] != "existing" {
+			t.Errorf("Expected record ID 'existing', got '%v'", record["id"])
+		}
+	})
+
+	t.Run("creates missing record", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+
+			if r.Method == "GET" {
+				json.NewEncoder(w).Encode(listResp{Page: 1, PerPage: 1})
+				return
+			}
+			json.NewEncoder(w).Encode(Record{"id": "new", "slug": "go"})
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+
+		record, created, err := client.CreateRecordIfNotExists(context.Background(), "tags", "slug='go'", Record{"slug": "go"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !created {
+			t.Error("Expected created to be true")
+		}
+		if record["id"] != "new" {
+			t.Errorf("Expected record ID 'new', got '%v'", record["id"])
+		}
+	})
+
+	t.Run("handles concurrent create", func(t *testing.T) {
+		lookups := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+
+			if r.Method == "GET" {
+				lookups++
+				response := listResp{Page: 1, PerPage: 1}
+				if lookups > 1 {
+					response.Items = []Record{{"id": "concurrent"}}
+				}
+				json.NewEncoder(w).Encode(response)
+				return
+			}
+
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(apiErrorResp{
+				Status:  400,
+				Message: "Failed to create record.",
+				Data: map[string]any{
+					"slug": map[string]string{
+						"code":    "validation_not_unique",
+						"message": "Value must be unique.",
+					},
+				},
+			})
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+
+		record, created, err := client.CreateRecordIfNotExists(context.Background(), "tags", "slug='go'", Record{"slug": "go"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if created {
+			t.Error("Expected created to be false")
+		}
+		if record["id"] != "concurrent" {
+			t.Errorf("Expected record ID 'concurrent', got '%v'", record["id"])
+		}
+	})
+}
+
+func TestClient_Send(t *testing.T) {
+	t.Run("sends raw body as-is", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != "POST" {
+				t.Errorf("Expected POST method, got %s", r.Method)
+			}
+			if r.URL.Path != "/api/myapp/import" {
+				t.Errorf("Expected path '/api/myapp/import', got '%s'", r.URL.Path)
+			}
+			if r.Header.Get("Content-Type") != "text/plain" {
+				t.Errorf("Expected Content-Type 'text/plain', got '%s'", r.Header.Get("Content-Type"))
+			}
+
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != "hello, world" {
+				t.Errorf("Expected raw body 'hello, world', got '%s'", body)
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{"imported": 1})
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+
+		var result map[string]any
+		err := client.Send(context.Background(), "POST", "/api/myapp/import",
+			&RawBody{Reader: strings.NewReader("hello, world"), ContentType: "text/plain"}, &result)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result["imported"] != float64(1) {
+			t.Errorf("Expected imported 1, got %v", result["imported"])
+		}
+	})
+
+	t.Run("does not double encode bytes", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Content-Type") != "application/octet-stream" {
+				t.Errorf("Expected Content-Type 'application/octet-stream', got '%s'", r.Header.Get("Content-Type"))
+			}
+
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != `{"already":"encoded"}` {
+				t.Errorf("Expected body to be sent unchanged, got '%s'", body)
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+
+		err := client.Send(context.Background(), "POST", "api/myapp/raw", []byte(`{"already":"encoded"}`), nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	})
+}
+
+// testToken creates an unsigned JWT token with the given claims for testing.
+func testToken(claims map[string]any) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payload, _ := json.Marshal(claims)
+	return header + "." + base64.RawURLEncoding.EncodeToString(payload) + ".signature"
+}
+
+func TestClient_TokenExpiry(t *testing.T) {
+	t.Run("reads exp claim", func(t *testing.T) {
+		exp := time.Now().Add(time.Hour).Truncate(time.Second)
+		client := NewClient("http://localhost:8090")
+		client.SetToken(testToken(map[string]any{"exp": exp.Unix()}))
+
+		expiry, ok := client.TokenExpiry()
+		if !ok {
+			t.Fatal("Expected token expiry to be available")
+		}
+		if !expiry.Equal(exp) {
+			t.Errorf("Expected expiry %v, got %v", exp, expiry)
+		}
+		if client.IsTokenExpired() {
+			t.Error("Expected token not to be expired")
+		}
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		client := NewClient("http://localhost:8090")
+		client.SetToken(testToken(map[string]any{"exp": time.Now().Add(-time.Minute).Unix()}))
+
+		if !client.IsTokenExpired() {
+			t.Error("Expected token to be expired")
+		}
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		client := NewClient("http://localhost:8090")
+		client.SetToken("not-a-jwt")
+
+		if _, ok := client.TokenExpiry(); ok {
+			t.Error("Expected token expiry to be unavailable")
+		}
+		if !client.IsTokenExpired() {
+			t.Error("Expected invalid token to be considered expired")
+		}
+	})
+}
+
+func TestClient_AuthRefresh(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("Expected POST method, got %s", r.Method)
+		}
+		if r.URL.Path != "/api/collections/users/auth-refresh" {
+			t.Errorf("Expected path '/api/collections/users/auth-refresh', got '%s'", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "old-token" {
+			t.Errorf("Expected Authorization header 'old-token', got '%s'", r.Header.Get("Authorization"))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(authResp{Token: "new-token", Record: Record{"id": "user-1"}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.SetToken("old-token")
+
+	record, err := client.AuthRefresh(context.Background(), "users")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if record["id"] != "user-1" {
+		t.Errorf("Expected record ID 'user-1', got '%v'", record["id"])
+	}
+	if client.GetToken() != "new-token" {
+		t.Errorf("Expected stored token 'new-token', got '%s'", client.GetToken())
+	}
+}
+
+func TestClient_StartAutoRefresh(t *testing.T) {
+	refreshedToken := testToken(map[string]any{"exp": time.Now().Add(time.Hour).Unix()})
+	refreshed := make(chan struct{}, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/collections/users/auth-refresh" {
+			t.Errorf("Unexpected path '%s'", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(authResp{Token: refreshedToken, Record: Record{"id": "user-1"}})
+		refreshed <- struct{}{}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.SetToken(testToken(map[string]any{"exp": time.Now().Add(time.Second).Unix()}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Refresh is due immediately because the lead time exceeds the remaining validity
+	client.StartAutoRefresh(ctx, "users", time.Minute)
+
+	select {
+	case <-refreshed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected token to be refreshed")
+	}
+
+	// Wait for the refreshed token to be stored
+	deadline := time.Now().Add(5 * time.Second)
+	for client.GetToken() != refreshedToken && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if client.GetToken() != refreshedToken {
+		t.Error("Expected refreshed token to be stored")
+	}
+}
+
+func TestClient_StartAutoRefresh_LeadTimeExceedsLifetime(t *testing.T) {
+	var refreshes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshes.Add(1)
+
+		// A short-lived token, always due for refresh with the lead time below
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(authResp{Token: testToken(map[string]any{"exp": time.Now().Add(time.Minute).Unix()})})
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := NewClient(server.URL)
+	client.SetToken(testToken(map[string]any{"exp": time.Now().Add(time.Minute).Unix()}))
+	client.StartAutoRefresh(ctx, "users", time.Hour)
+
+	time.Sleep(1500 * time.Millisecond)
+	cancel()
+
+	// One refresh right away, then at most one per second
+	if n := refreshes.Load(); n < 1 || n > 2 {
+		t.Errorf("Expected 1 or 2 refreshes, got %d", n)
+	}
+}
+
+func TestClient_AuthenticateWithPassword_Unverified(t *testing.T) {
+	// Mock server that rejects the login of an unverified account
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+
+		response := apiErrorResp{
+			Status:  403,
+			Message: "Please verify your account first.",
+			Data:    map[string]any{},
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	_, err := client.AuthenticateWithPassword(context.Background(), "users", "alice@example.com", "password123")
+
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("Expected APIError, got %T", err)
+	}
+	if !apiErr.IsEmailNotVerified() {
+		t.Error("Expected IsEmailNotVerified() to return true")
+	}
+
+	invalidCredentials := &APIError{Status: 400, Message: "Failed to authenticate."}
+	if invalidCredentials.IsEmailNotVerified() {
+		t.Error("Expected IsEmailNotVerified() to return false for invalid credentials")
+	}
+}
+
+func TestWithClientTrace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Record{"id": "record-1"})
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	traces := 0
+	firstBytes := 0
+
+	client := NewClient(server.URL, WithClientTrace(func() *httptrace.ClientTrace {
+		mu.Lock()
+		traces++
+		mu.Unlock()
+
+		return &httptrace.ClientTrace{
+			GotFirstResponseByte: func() {
+				mu.Lock()
+				firstBytes++
+				mu.Unlock()
+			},
+		}
+	}))
+
+	// A trace already present in the caller's context must still be invoked
+	callerTraced := false
+	ctx := httptrace.WithClientTrace(context.Background(), &httptrace.ClientTrace{
+		GotFirstResponseByte: func() {
+			callerTraced = true
+		},
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetRecord(ctx, "posts", "record-1"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if traces != 2 {
+		t.Errorf("Expected a fresh trace per request (2), got %d", traces)
+	}
+	if firstBytes != 2 {
+		t.Errorf("Expected GotFirstResponseByte to be called 2 times, got %d", firstBytes)
+	}
+	if !callerTraced {
+		t.Error("Expected the caller's trace to be invoked too")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	original := Record{
+		"id":       "record-1",
+		"title":    "Original",
+		"views":    float64(10),
+		"tags":     []any{"go", "api"},
+		"meta":     map[string]any{"lang": "en", "draft": true},
+		"obsolete": "value",
+	}
+
+	edited := Record{
+		"id":    "record-1",
+		"title": "Changed",
+		"views": 10, // same value, different numeric type
+		"tags":  []string{"go", "api"},
+		"meta":  map[string]any{"lang": "de", "draft": true},
+		"added": "new",
+	}
+
+	changes := Diff(original, edited)
+
+	expected := Record{
+		"title":    "Changed",
+		"meta":     map[string]any{"lang": "de", "draft": true},
+		"added":    "new",
+		"obsolete": nil,
+	}
+
+	if len(changes) != len(expected) {
+		t.Errorf("Expected %d changed fields, got %d: %v", len(expected), len(changes), changes)
+	}
+	for key, value := range expected {
+		changed, ok := changes[key]
+		if !ok {
+			t.Errorf("Expected field '%s' to be changed", key)
+			continue
+		}
+		if !valuesEqual(changed, value) {
+			t.Errorf("Expected field '%s' to be %v, got %v", key, value, changed)
+		}
+	}
+
+	if len(Diff(original, original)) != 0 {
+		t.Error("Expected no changes when diffing a record with itself")
+	}
+}
+
+func TestWithDefaultPerPage(t *testing.T) {
+	var perPage string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		perPage = r.URL.Query().Get("perPage")
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(listResp{Page: 1, TotalPages: 1})
+	}))
+	defer server.Close()
+
+	t.Run("client default is used without per-call option", func(t *testing.T) {
+		client := NewClient(server.URL, WithDefaultPerPage(200))
+
+		if _, err := client.GetAllRecords(context.Background(), "posts"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if perPage != "200" {
+			t.Errorf("Expected perPage parameter '200', got '%s'", perPage)
+		}
+	})
+
+	t.Run("per-call option overrides client default", func(t *testing.T) {
+		client := NewClient(server.URL, WithDefaultPerPage(200))
+
+		if _, err := client.GetAllRecords(context.Background(), "posts", WithPerPage(10)); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if perPage != "10" {
+			t.Errorf("Expected perPage parameter '10', got '%s'", perPage)
+		}
+	})
+
+	t.Run("clamps to server maximum", func(t *testing.T) {
+		client := NewClient(server.URL, WithDefaultPerPage(5000))
+
+		if _, err := client.GetAllRecords(context.Background(), "posts"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if perPage != "1000" {
+			t.Errorf("Expected perPage parameter '1000', got '%s'", perPage)
+		}
+	})
+}
+
+func TestClient_AuthWithOAuth2Code(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expectedPath := "/api/collections/users/auth-with-oauth2"
+		if r.URL.Path != expectedPath {
+			t.Errorf("Expected path '%s', got '%s'", expectedPath, r.URL.Path)
+		}
+
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+
+		expectedFields := map[string]string{
+			"provider":     "google",
+			"code":         "auth-code",
+			"codeVerifier": "verifier",
+			"redirectURL":  "http://localhost:8080/callback",
+		}
+		for key, expected := range expectedFields {
+			if body[key] != expected {
+				t.Errorf("Expected %s '%s', got '%v'", key, expected, body[key])
+			}
+		}
+
+		createData, ok := body["createData"].(map[string]any)
+		if !ok {
+			t.Fatalf("Expected createData object in request body, got %v", body["createData"])
+		}
+		if createData["username"] != "alice" {
+			t.Errorf("Expected createData username 'alice', got '%v'", createData["username"])
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(authResp{Token: "oauth2-token", Record: Record{"id": "user-1", "username": "alice"}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	record, err := client.AuthWithOAuth2Code(context.Background(), "users", "google", "auth-code", "verifier",
+		"http://localhost:8080/callback", Record{"username": "alice"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if record["id"] != "user-1" {
+		t.Errorf("Expected record ID 'user-1', got '%v'", record["id"])
+	}
+	if client.GetToken() != "oauth2-token" {
+		t.Errorf("Expected stored token 'oauth2-token', got '%s'", client.GetToken())
+	}
+}
+
+func TestClient_AuthWithOAuth2CodeResult(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"token": "oauth2-token",
+			"record": {"id": "user-1"},
+			"meta": {
+				"name": "Alice Smith",
+				"avatarURL": "https://example.com/alice.png",
+				"isNew": true,
+				"rawUser": {"locale": "en"}
+			}
+		}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	result, err := client.AuthWithOAuth2CodeResult(context.Background(), "users", "google", "auth-code", "verifier",
+		"http://localhost:8080/callback", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Token != "oauth2-token" || result.Record["id"] != "user-1" {
+		t.Errorf("Expected token and record, got %+v", result)
+	}
+	if result.Meta["name"] != "Alice Smith" || result.Meta["avatarURL"] != "https://example.com/alice.png" {
+		t.Errorf("Expected provider profile in meta, got %v", result.Meta)
+	}
+	if result.Meta["isNew"] != true {
+		t.Errorf("Expected isNew meta to be true, got %v", result.Meta["isNew"])
+	}
+	if raw, _ := result.Meta["rawUser"].(map[string]any); raw["locale"] != "en" {
+		t.Errorf("Expected nested rawUser meta, got %v", result.Meta["rawUser"])
+	}
+	if client.GetToken() != "oauth2-token" {
+		t.Errorf("Expected stored token 'oauth2-token', got '%s'", client.GetToken())
+	}
+}
+
+func TestClient_Impersonate_NegativeDuration(t *testing.T) {
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.SetToken("superuser-token")
+
+	_, err := client.Impersonate(context.Background(), "users", "user-id-123", -1)
+	if err == nil {
+		t.Fatal("Expected error for negative duration")
+	}
+	if _, ok := err.(*APIError); ok {
+		t.Error("Expected a validation error, not an APIError")
+	}
+	if requestCount != 0 {
+		t.Errorf("Expected no requests to be made, got %d", requestCount)
+	}
+}
+
+func TestClient_RedirectDroppingAuth(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Record{"id": "record-1"})
+	}))
+	defer target.Close()
+
+	// Redirect to a different host name so that Go drops the Authorization header
+	targetURL := strings.Replace(target.URL, "127.0.0.1", "localhost", 1)
+	redirecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, targetURL+r.URL.Path, http.StatusTemporaryRedirect)
+	}))
+	defer redirecting.Close()
+
+	t.Run("warns when auth header is dropped", func(t *testing.T) {
+		var logs strings.Builder
+		client := NewClient(redirecting.URL, WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+		client.SetToken("test-token")
+
+		if _, err := client.GetRecord(context.Background(), "posts", "record-1"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if !strings.Contains(logs.String(), "authentication header was dropped on redirect") {
+			t.Errorf("Expected redirect warning to be logged, got '%s'", logs.String())
+		}
+	})
+
+	t.Run("does not warn without redirect", func(t *testing.T) {
+		var logs strings.Builder
+		client := NewClient(target.URL, WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+		client.SetToken("test-token")
+
+		if _, err := client.GetRecord(context.Background(), "posts", "record-1"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if logs.Len() != 0 {
+			t.Errorf("Expected nothing to be logged, got '%s'", logs.String())
+		}
+	})
+
+	t.Run("redirect policy controls following", func(t *testing.T) {
+		client := NewClient(redirecting.URL, WithRedirectPolicy(func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}))
+
+		_, err := client.GetRecord(context.Background(), "posts", "record-1")
+
+		apiErr, ok := err.(*APIError)
+		if !ok {
+			t.Fatalf("Expected APIError, got %T", err)
+		}
+		if apiErr.Status != http.StatusTemporaryRedirect {
+			t.Errorf("Expected error status 307, got %d", apiErr.Status)
+		}
+		if client.HTTPClient.CheckRedirect != nil {
+			t.Error("Expected the configured HTTP client not to be modified")
+		}
+	})
+}
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		params   map[string]any
+		expected string
+	}{
+		{"string", "title = {:title}", map[string]any{"title": "hello"}, "title = 'hello'"},
+		{"escapes quotes", "title = {:title}", map[string]any{"title": "it's' || 1=1"}, `title = 'it\'s\' || 1=1'`},
+		{"number", "views > {:views}", map[string]any{"views": 10}, "views > 10"},
+		{"float", "rating >= {:rating}", map[string]any{"rating": 4.5}, "rating >= 4.5"},
+		{"bool", "active = {:active}", map[string]any{"active": true}, "active = true"},
+		{"nil", "deleted = {:deleted}", map[string]any{"deleted": nil}, "deleted = null"},
+		{"time", "created > {:since}", map[string]any{"since": time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}, "created > '2024-01-02 03:04:05.000Z'"},
+		{"missing param", "title = {:title}", map[string]any{}, "title = {:title}"},
+		{"macro is bare", "expires > {:now}", map[string]any{"now": MacroNow}, "expires > @now"},
+		{"macro string is quoted", "title = {:title}", map[string]any{"title": "@now"}, "title = '@now'"},
+		{"macro with string", "created >= {:start} && title = {:title}", map[string]any{"start": MacroTodayStart, "title": "daily"}, "created >= @todayStart && title = 'daily'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Filter(tt.expr, tt.params); got != tt.expected {
+				t.Errorf("Expected filter '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestClient_GetRecordByField(t *testing.T) {
+	newServer := func(items []Record) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+
+			expectedFilter := `slug = 'it\'s-here'`
+			if query.Get("filter") != expectedFilter {
+				t.Errorf("Expected filter '%s', got '%s'", expectedFilter, query.Get("filter"))
+			}
+			if query.Get("perPage") != "2" {
+				t.Errorf("Expected perPage parameter '2', got '%s'", query.Get("perPage"))
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(listResp{Page: 1, PerPage: 2, TotalPages: 1, Items: items})
+		}))
+	}
+
+	t.Run("single match", func(t *testing.T) {
+		server := newServer([]Record{{"id": "record-1"}})
+		defer server.Close()
+
+		client := NewClient(server.URL)
+
+		record, err := client.GetRecordByField(context.Background(), "posts", "slug", "it's-here")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if record["id"] != "record-1" {
+			t.Errorf("Expected record ID 'record-1', got '%v'", record["id"])
+		}
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		server := newServer(nil)
+		defer server.Close()
+
+		client := NewClient(server.URL)
+
+		_, err := client.GetRecordByField(context.Background(), "posts", "slug", "it's-here")
+
+		apiErr, ok := err.(*APIError)
+		if !ok {
+			t.Fatalf("Expected APIError, got %T", err)
+		}
+		if !apiErr.IsNotFound() {
+			t.Error("Expected IsNotFound() to return true")
+		}
+	})
+
+	t.Run("multiple matches", func(t *testing.T) {
+		server := newServer([]Record{{"id": "record-1"}, {"id": "record-2"}})
+		defer server.Close()
+
+		client := NewClient(server.URL)
+
+		_, err := client.GetRecordByField(context.Background(), "posts", "slug", "it's-here")
+		if !errors.Is(err, ErrMultipleRecords) {
+			t.Errorf("Expected ErrMultipleRecords, got %v", err)
+		}
+	})
+
+	t.Run("invalid field name", func(t *testing.T) {
+		client := NewClient("http://localhost:8090")
+
+		_, err := client.GetRecordByField(context.Background(), "posts", "slug = '' || id", "x")
+		if err == nil {
+			t.Error("Expected error for invalid field name")
+		}
+	})
+}
+
+func TestWithFieldPreset(t *testing.T) {
+	var fields string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fields = r.URL.Query().Get("fields")
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/collections/posts/records" {
+			json.NewEncoder(w).Encode(listResp{Page: 1, TotalPages: 1})
+			return
+		}
+		json.NewEncoder(w).Encode(Record{"id": "record-1"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithFieldPreset("card", []string{"id", "title", "thumbnail"}))
+
+	t.Run("expands preset for single record", func(t *testing.T) {
+		if _, err := client.GetRecord(context.Background(), "posts", "record-1", WithFieldsPreset("card")); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if fields != "id,title,thumbnail" {
+			t.Errorf("Expected fields 'id,title,thumbnail', got '%s'", fields)
+		}
+	})
+
+	t.Run("expands preset for lists", func(t *testing.T) {
+		_, err := client.GetAllRecords(context.Background(), "posts",
+			WithListFieldsPreset("card"), WithListFields("author"))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if fields != "id,title,thumbnail,author" {
+			t.Errorf("Expected fields 'id,title,thumbnail,author', got '%s'", fields)
+		}
+	})
+
+	t.Run("unknown preset returns error", func(t *testing.T) {
+		_, err := client.GetRecord(context.Background(), "posts", "record-1", WithFieldsPreset("missing"))
+		if err == nil || !strings.Contains(err.Error(), `unknown fields preset "missing"`) {
+			t.Errorf("Expected unknown preset error, got %v", err)
+		}
+	})
+}
+
+func TestClient_CountByField(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fields := r.URL.Query().Get("fields"); fields != "category" {
+			t.Errorf("Expected fields 'category', got '%s'", fields)
+		}
+
+		response := listResp{
+			Page:       1,
+			PerPage:    30,
+			TotalItems: 5,
+			TotalPages: 1,
+			Items: []Record{
+				{"category": "news"},
+				{"category": "news"},
+				{"category": "sports"},
+				{"category": []any{"news", "tech"}},
+				{},
+			},
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	counts, err := client.CountByField(context.Background(), "posts", "category")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]int{"news": 3, "sports": 1, "tech": 1, "": 1}
+	if len(counts) != len(expected) {
+		t.Errorf("Expected %d groups, got %d: %v", len(expected), len(counts), counts)
+	}
+	for value, count := range expected {
+		if counts[value] != count {
+			t.Errorf("Expected count %d for '%s', got %d", count, value, counts[value])
+		}
+	}
+	// The field selection isn't written into the spare capacity of the caller's options
+	opts := make([]ListOption, 1, 2)
+	opts[0] = WithSort("created")
+	if _, err := client.CountByField(context.Background(), "posts", "category", opts...); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if extra := opts[:2][1]; extra != nil {
+		t.Error("Expected the caller's options to be left unchanged")
+	}
+}
+
+func TestWithTLSClientCert(t *testing.T) {
+	cert := tls.Certificate{Certificate: [][]byte{[]byte("test-certificate")}}
+	client := NewClient("https://example.com",
+		WithTLSClientCert(cert),
+		WithMinTLSVersion(tls.VersionTLS13))
+
+	transport, ok := client.HTTPClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("Expected *http.Transport, got %T", client.HTTPClient.Transport)
+	}
+	config := transport.TLSClientConfig
+	if config == nil || len(config.Certificates) != 1 || string(config.Certificates[0].Certificate[0]) != "test-certificate" {
+		t.Fatalf("Expected the client certificate in the TLS config, got %+v", config)
+	}
+	if config.MinVersion != tls.VersionTLS13 {
+		t.Errorf("Expected MinVersion TLS 1.3 to be kept, got %d", config.MinVersion)
+	}
+}
+
+func TestWithMinTLSVersion(t *testing.T) {
+	t.Run("applies to the transport in any option order", func(t *testing.T) {
+		client := NewClient("https://example.com",
+			WithMinTLSVersion(tls.VersionTLS13),
+			WithTimeout(5*time.Second))
+
+		transport, ok := client.HTTPClient.Transport.(*http.Transport)
+		if !ok {
+			t.Fatalf("Expected *http.Transport, got %T", client.HTTPClient.Transport)
+		}
+		if transport.TLSClientConfig == nil || transport.TLSClientConfig.MinVersion != tls.VersionTLS13 {
+			t.Errorf("Expected MinVersion TLS 1.3, got %+v", transport.TLSClientConfig)
+		}
+		if client.HTTPClient.Timeout != 5*time.Second {
+			t.Errorf("Expected timeout 5s, got %v", client.HTTPClient.Timeout)
+		}
+	})
+
+	t.Run("does not modify the provided HTTP client", func(t *testing.T) {
+		httpClient := &http.Client{}
+		client := NewClient("https://example.com",
+			WithHTTPClient(httpClient),
+			WithMinTLSVersion(tls.VersionTLS13))
+
+		if httpClient.Transport != nil {
+			t.Error("Expected provided HTTP client transport to be left untouched")
+		}
+		if client.HTTPClient == httpClient {
+			t.Error("Expected client to use a copy of the provided HTTP client")
+		}
+	})
+
+	t.Run("rejects servers below the minimum version", func(t *testing.T) {
+		// Mock server that only speaks TLS 1.2
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(Record{"id": "record-1"})
+		}))
+		server.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+		server.StartTLS()
+		defer server.Close()
+
+		client := NewClient(server.URL, WithHTTPClient(server.Client()))
+		if _, err := client.GetRecord(context.Background(), "posts", "record-1"); err != nil {
+			t.Fatalf("Expected TLS 1.2 to be accepted by default, got %v", err)
+		}
+
+		client = NewClient(server.URL, WithHTTPClient(server.Client()), WithMinTLSVersion(tls.VersionTLS13))
+		if _, err := client.GetRecord(context.Background(), "posts", "record-1"); err == nil {
+			t.Error("Expected handshake error with minimum TLS 1.3, got nil")
+		}
+	})
+}
+
+func TestClient_UpdateRecordsByFilter(t *testing.T) {
+	// Mock server listing three drafts, where updating record-2 fails
+	newServer := func(t *testing.T, patched *[]string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+
+			if r.Method == "GET" {
+				if filter := r.URL.Query().Get("filter"); filter != "status='draft'" {
+					t.Errorf("Expected filter \"status='draft'\", got '%s'", filter)
+				}
+				json.NewEncoder(w).Encode(listResp{
+					Page:       1,
+					PerPage:    1000,
+					TotalItems: 3,
+					TotalPages: 1,
+					Items:      []Record{{"id": "record-1"}, {"id": "record-2"}, {"id": "record-3"}},
+				})
+				return
+			}
+
+			if r.Method != "PATCH" {
+				t.Errorf("Expected PATCH request, got %s", r.Method)
+			}
+
+			var body Record
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["status"] != "archived" {
+				t.Errorf("Expected status 'archived', got '%v'", body["status"])
+			}
+
+			id := strings.TrimPrefix(r.URL.Path, "/api/collections/posts/records/")
+			*patched = append(*patched, id)
+			if id == "record-2" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(apiErrorResp{Status: 400, Message: "Failed to update record."})
+				return
+			}
+			json.NewEncoder(w).Encode(Record{"id": id, "status": "archived"})
+		}))
+	}
+
+	t.Run("stops at the first failure", func(t *testing.T) {
+		var patched []string
+		server := newServer(t, &patched)
+		defer server.Close()
+
+		client := NewClient(server.URL)
+		updated, err := client.UpdateRecordsByFilter(context.Background(), "posts", "status='draft'",
+			Record{"status": "archived"})
+		if err == nil {
+			t.Fatal("Expected error, got nil")
+		}
+		if updated != 1 {
+			t.Errorf("Expected 1 updated record, got %d", updated)
+		}
+		if len(patched) != 2 {
+			t.Errorf("Expected 2 update requests, got %v", patched)
+		}
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || !apiErr.IsBadRequest() {
+			t.Errorf("Expected bad request APIError, got %v", err)
+		}
+	})
+
+	t.Run("continues on error", func(t *testing.T) {
+		var patched []string
+		server := newServer(t, &patched)
+		defer server.Close()
+
+		client := NewClient(server.URL)
+		updated, err := client.UpdateRecordsByFilter(context.Background(), "posts", "status='draft'",
+			Record{"status": "archived"}, WithContinueOnError())
+		if err == nil {
+			t.Fatal("Expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "record-2") {
+			t.Errorf("Expected error to mention 'record-2', got %v", err)
+		}
+		if updated != 2 {
+			t.Errorf("Expected 2 updated records, got %d", updated)
+		}
+		if len(patched) != 3 {
+			t.Errorf("Expected 3 update requests, got %v", patched)
+		}
+	})
+
+	t.Run("applies query options to each update", func(t *testing.T) {
+		var fields []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.Method == "GET" {
+				json.NewEncoder(w).Encode(listResp{
+					Page:       1,
+					PerPage:    1000,
+					TotalItems: 2,
+					TotalPages: 1,
+					Items:      []Record{{"id": "record-1"}, {"id": "record-2"}},
+				})
+				return
+			}
+			fields = append(fields, r.URL.Query().Get("fields"))
+			json.NewEncoder(w).Encode(Record{"id": "record-1"})
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+		updated, err := client.UpdateRecordsByFilter(context.Background(), "posts", "status='draft'",
+			Record{"status": "archived"}, WithBulkQuery(WithFields("id", "status")))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if updated != 2 {
+			t.Errorf("Expected 2 updated records, got %d", updated)
+		}
+		if !slices.Equal(fields, []string{"id,status", "id,status"}) {
+			t.Errorf("Expected fields 'id,status' on each update, got %v", fields)
+		}
+	})
+}
+
+func TestClient_UploadUsesContextDeadline(t *testing.T) {
+	// Mock server that takes longer to accept the upload than the client timeout
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		time.Sleep(300 * time.Millisecond)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Record{"id": "record-1"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithTimeout(100*time.Millisecond))
+	upload := func() FileUploadOption {
+		return WithFileUpload("document", []FileData{CreateFileDataFromBytes([]byte("data"), "doc.txt")})
+	}
+
+	t.Run("long context deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if _, err := client.CreateRecordWithFiles(ctx, "documents", upload()); err != nil {
+			t.Errorf("Expected upload to succeed under context deadline, got %v", err)
+		}
+	})
+
+	t.Run("no context deadline", func(t *testing.T) {
+		if _, err := client.CreateRecordWithFiles(context.Background(), "documents", upload()); err == nil {
+			t.Error("Expected client timeout error, got nil")
+		}
+	})
+}
+
+func TestRecord_FileNames(t *testing.T) {
+	record := Record{
+		"avatar":      "avatar_abc123.jpg",
+		"attachments": []any{"doc_1.pdf", "doc_2.pdf"},
+		"empty":       "",
+		"emptyList":   []any{},
+	}
+
+	tests := []struct {
+		field    string
+		expected []string
+	}{
+		{"avatar", []string{"avatar_abc123.jpg"}},
+		{"attachments", []string{"doc_1.pdf", "doc_2.pdf"}},
+		{"empty", nil},
+		{"emptyList", nil},
+		{"missing", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			names := record.FileNames(tt.field)
+			if tt.expected == nil {
+				if names != nil {
+					t.Errorf("Expected nil, got %v", names)
+				}
+				return
+			}
+			if strings.Join(names, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestImpersonateResult_NewClient(t *testing.T) {
+	result := &ImpersonateResult{Token: "impersonation-token", Record: Record{"id": "user-1"}}
+
+	client := result.NewClient("http://localhost:8090/", WithUserAgent("TestApp/1.0"))
+
+	if client.GetToken() != "impersonation-token" {
+		t.Errorf("Expected token 'impersonation-token', got '%s'", client.GetToken())
+	}
+	if client.BaseURL != "http://localhost:8090" {
+		t.Errorf("Expected BaseURL 'http://localhost:8090', got '%s'", client.BaseURL)
+	}
+	if client.userAgent != "TestApp/1.0" {
+		t.Errorf("Expected user agent 'TestApp/1.0', got '%s'", client.userAgent)
+	}
+}
+
+func TestAPIError_Verbose(t *testing.T) {
+	apiErr := &APIError{
+		Status:  400,
+		Message: "Failed to create record.",
+		Data: map[string]any{
+			"title": map[string]any{"code": "validation_required", "message": "Missing required value."},
+			"slug":  map[string]any{"code": "validation_not_unique", "message": "Value must be unique."},
+			"meta":  map[string]any{"tags": []any{"a", "b"}},
+		},
+	}
+
+	expected := "pocketbase API error: 400 Failed to create record.\n" +
+		"  meta: {\"tags\":[\"a\",\"b\"]}\n" +
+		"  slug: validation_not_unique: Value must be unique.\n" +
+		"  title: validation_required: Missing required value."
+	if verbose := apiErr.Verbose(); verbose != expected {
+		t.Errorf("Expected verbose output:\n%s\ngot:\n%s", expected, verbose)
+	}
+
+	// Error stays terse
+	if apiErr.Error() != "pocketbase API error: 400 Failed to create record." {
+		t.Errorf("Expected terse error, got '%s'", apiErr.Error())
+	}
+}
+
+func TestWithRequestUserAgent(t *testing.T) {
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/collections/posts/records" {
+			json.NewEncoder(w).Encode(listResp{Page: 1, PerPage: 30, TotalPages: 1})
+			return
+		}
+		json.NewEncoder(w).Encode(Record{"id": "record-1"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithUserAgent("MainApp/1.0"))
+	ctx := context.Background()
+
+	if _, err := client.GetRecord(ctx, "posts", "record-1", WithRequestUserAgent("Billing/2.0")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := client.GetRecord(ctx, "posts", "record-1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := client.GetAllRecords(ctx, "posts", WithListRequestUserAgent("Reports/3.0")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{"Billing/2.0", "MainApp/1.0", "Reports/3.0"}
+	if strings.Join(userAgents, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected user agents %v, got %v", expected, userAgents)
+	}
+}
+
+func TestClient_GetAllLogs(t *testing.T) {
+	// Mock server serving 5 log entries over 3 pages
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/logs" {
+			t.Errorf("Expected path '/api/logs', got '%s'", r.URL.Path)
+		}
+		if filter := r.URL.Query().Get("filter"); filter != "level > 0" {
+			t.Errorf("Expected filter 'level > 0', got '%s'", filter)
+		}
+
+		page := r.URL.Query().Get("page")
+		var items []Record
+		switch page {
+		case "1":
+			items = []Record{{"id": "log-1"}, {"id": "log-2"}}
+		case "2":
+			items = []Record{{"id": "log-3"}, {"id": "log-4"}}
+		case "3":
+			items = []Record{{"id": "log-5"}}
+		default:
+			t.Errorf("Unexpected page '%s'", page)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(listResp{
+			PerPage:    2,
+			TotalItems: 5,
+			TotalPages: 3,
+			Items:      items,
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	ctx := context.Background()
+
+	t.Run("collects all pages", func(t *testing.T) {
+		logs, err := client.GetAllLogs(ctx, WithFilter("level > 0"), WithPerPage(2))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(logs) != 5 {
+			t.Fatalf("Expected 5 log entries, got %d", len(logs))
+		}
+		if logs[4]["id"] != "log-5" {
+			t.Errorf("Expected last entry 'log-5', got '%v'", logs[4]["id"])
+		}
+	})
+
+	t.Run("streams pages to the callback", func(t *testing.T) {
+		var pages [][]Record
+		logs, err := client.GetAllLogs(ctx, WithFilter("level > 0"), WithPerPage(2),
+			WithPageCallback(func(items []Record) error {
+				pages = append(pages, items)
+				return nil
+			}))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(logs) != 0 {
+			t.Errorf("Expected no collected entries, got %d", len(logs))
+		}
+		if len(pages) != 3 {
+			t.Errorf("Expected 3 pages, got %d", len(pages))
+		}
+	})
+
+	t.Run("callback error stops pagination", func(t *testing.T) {
+		stop := errors.New("stop")
+		calls := 0
+		_, err := client.GetAllLogs(ctx, WithFilter("level > 0"), WithPerPage(2),
+			WithPageCallback(func(items []Record) error {
+				calls++
+				return stop
+			}))
+		if !errors.Is(err, stop) {
+			t.Errorf("Expected callback error, got %v", err)
+		}
+		if calls != 1 {
+			t.Errorf("Expected 1 callback call, got %d", calls)
+		}
+	})
+}
+
+func TestClient_GetAllLogs_Truncated(t *testing.T) {
+	// Mock server reporting more log entries than GetAllLogs keeps in memory
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		items := make([]Record, 0, 3000)
+		for i := range 3000 {
+			items = append(items, Record{"id": fmt.Sprintf("log-%d", (page-1)*3000+i+1)})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(listResp{
+			Page:       page,
+			PerPage:    3000,
+			TotalItems: 30000,
+			TotalPages: 10,
+			Items:      items,
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	logs, err := client.GetAllLogs(context.Background(), WithPerPage(3000))
+	if !errors.Is(err, ErrLimitReached) {
+		t.Fatalf("Expected ErrLimitReached, got %v", err)
+	}
+	if len(logs) != defaultMaxLogs {
+		t.Fatalf("Expected %d log entries, got %d", defaultMaxLogs, len(logs))
+	}
+	if logs[defaultMaxLogs-1]["id"] != "log-10000" {
+		t.Errorf("Expected last entry 'log-10000', got '%v'", logs[defaultMaxLogs-1]["id"])
+	}
+	if got := requests.Load(); got != 4 {
+		t.Errorf("Expected 4 requests, got %d", got)
+	}
+}
+
+func TestClient_TruncateCollectionFast(t *testing.T) {
+	t.Run("uses the truncate endpoint", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != "DELETE" || r.URL.Path != "/api/collections/posts/truncate" {
+				t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+		if err := client.TruncateCollectionFast(context.Background(), "posts"); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("falls back to deleting records one by one", func(t *testing.T) {
+		// Mock server without the truncate endpoint
+		remaining := []Record{{"id": "record-1"}, {"id": "record-2"}}
+		var deleted []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+
+			switch {
+			case r.URL.Path == "/api/collections/posts/truncate":
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(apiErrorResp{Status: 404, Message: "The requested resource wasn't found."})
+			case r.Method == "GET" && r.URL.Path == "/api/collections/posts/records":
+				json.NewEncoder(w).Encode(listResp{Page: 1, PerPage: 1000, TotalPages: 1, Items: remaining})
+				remaining = nil
+			case r.Method == "DELETE":
+				deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/api/collections/posts/records/"))
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			}
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+		if err := client.TruncateCollectionFast(context.Background(), "posts"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if strings.Join(deleted, ",") != "record-1,record-2" {
+			t.Errorf("Expected records [record-1 record-2] deleted, got %v", deleted)
+		}
+	})
+}
+
+func TestWithIdleTimeout(t *testing.T) {
+	client := NewClient("http://localhost:8090", WithIdleTimeout(15*time.Second))
+
+	transport, ok := client.HTTPClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("Expected *http.Transport, got %T", client.HTTPClient.Transport)
+	}
+	if transport.IdleConnTimeout != 15*time.Second {
+		t.Errorf("Expected IdleConnTimeout 15s, got %v", transport.IdleConnTimeout)
+	}
+	if client.HTTPClient.Timeout != 0 {
+		t.Errorf("Expected request timeout to be unaffected, got %v", client.HTTPClient.Timeout)
+	}
+}
+
+func TestWithPreferIPv4(t *testing.T) {
+	var networks []string
+	base := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			networks = append(networks, network)
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"abc"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL,
+		WithHTTPClient(&http.Client{Transport: base}),
+		WithPreferIPv4())
+
+	transport, ok := client.HTTPClient.Transport.(*http.Transport)
+	if !ok || transport == base {
+		t.Fatalf("Expected a cloned *http.Transport, got %T", client.HTTPClient.Transport)
+	}
+	if transport.DialContext == nil {
+		t.Fatal("Expected a custom dialer to be installed")
+	}
+
+	if _, err := client.GetRecord(context.Background(), "posts", "abc"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !slices.Equal(networks, []string{"tcp4"}) {
+		t.Errorf("Expected the existing dialer to dial tcp4, got %v", networks)
+	}
+}
+
+func TestExpandFields(t *testing.T) {
+	tests := []struct {
+		relation string
+		fields   []string
+		expected []string
+	}{
+		{"author", []string{"name", "avatar"}, []string{"expand.author.name", "expand.author.avatar"}},
+		{"author.team", []string{"name"}, []string{"expand.author.expand.team.name"}},
+		{"tags", nil, []string{"expand.tags"}},
+	}
+	for _, tt := range tests {
+		if got := ExpandFields(tt.relation, tt.fields...); !slices.Equal(got, tt.expected) {
+			t.Errorf("ExpandFields(%q, %v): expected %v, got %v", tt.relation, tt.fields, tt.expected, got)
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expected := "id,title,expand.author.name,expand.author.avatar"
+		if fields := r.URL.Query().Get("fields"); fields != expected {
+			t.Errorf("Expected fields parameter '%s', got '%s'", expected, fields)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"post-1"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	fields := append([]string{"id", "title"}, ExpandFields("author", "name", "avatar")...)
+	if _, err := client.GetRecord(context.Background(), "posts", "post-1", WithExpand("author"), WithFields(fields...)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestRecord_HiddenExpands(t *testing.T) {
+	post := Record{
+		"id":       "post-1",
+		"author":   "user-1",
+		"editor":   "user-2",
+		"reviewer": "",
+		"tags":     []any{"tag-1", "tag-2"},
+		"expand": map[string]any{
+			"author": map[string]any{"id": "user-1", "team": "team-1"},
+			"tags": []any{
+				map[string]any{"id": "tag-1", "category": "cat-1", "expand": map[string]any{"category": map[string]any{"id": "cat-1"}}},
+				map[string]any{"id": "tag-2", "category": "cat-2"},
+			},
+		},
+	}
+
+	requested := []string{"author", "editor", "reviewer", "tags", "author.team", "tags.category", "comments_via_post"}
+	hidden := post.HiddenExpands(requested...)
+
+	expected := []string{"editor", "author.team", "tags.category"}
+	if !slices.Equal(hidden, expected) {
+		t.Errorf("Expected hidden expands %v, got %v", expected, hidden)
+	}
+
+	if hidden := post.HiddenExpands("author,editor"); !slices.Equal(hidden, []string{"editor"}) {
+		t.Errorf("Expected comma separated expands to be checked, got %v", hidden)
+	}
+}
+
+func TestRecord_ExpandList(t *testing.T) {
+	// Mock server returning a single and a multi-relation expand
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"id": "post-1",
+			"expand": {
+				"author": {"id": "user-1", "name": "Alice"},
+				"tags": [
+					{"id": "tag-2", "name": "go"},
+					{"id": "tag-1", "name": "pocketbase"}
+				]
+			}
+		}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	post, err := client.GetRecord(context.Background(), "posts", "post-1", WithExpand("author", "tags"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tags := post.ExpandList("tags")
+	if len(tags) != 2 {
+		t.Fatalf("Expected 2 tags, got %d", len(tags))
+	}
+	if tags[0]["id"] != "tag-2" || tags[1]["id"] != "tag-1" {
+		t.Errorf("Expected tags in order [tag-2 tag-1], got [%v %v]", tags[0]["id"], tags[1]["id"])
+	}
+	if post.Expand("tags") != nil {
+		t.Error("Expected Expand to return nil for a multi-relation field")
+	}
+
+	author := post.Expand("author")
+	if author["name"] != "Alice" {
+		t.Errorf("Expected author 'Alice', got '%v'", author["name"])
+	}
+	if authors := post.ExpandList("author"); len(authors) != 1 || authors[0]["id"] != "user-1" {
+		t.Errorf("Expected single relation as one-element list, got %v", authors)
+	}
+
+	if post.Expand("missing") != nil || post.ExpandList("missing") != nil {
+		t.Error("Expected nil for a field that wasn't expanded")
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	t.Run("replays the body after a network failure", func(t *testing.T) {
+		var bodies []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+
+			if len(bodies) == 1 {
+				// Simulate a network failure by dropping the connection
+				conn, _, _ := w.(http.Hijacker).Hijack()
+				conn.Close()
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{"ok": true})
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL, WithRetry(2))
+
+		var result map[string]any
+		err := client.Send(context.Background(), "PUT", "/api/settings", map[string]any{"name": "test"}, &result)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(bodies) != 2 {
+			t.Fatalf("Expected 2 attempts, got %d", len(bodies))
+		}
+		if bodies[1] != `{"name":"test"}` {
+			t.Errorf("Expected body to be sent intact on retry, got '%s'", bodies[1])
+		}
+	})
+
+	t.Run("retries 503 up to the limit", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL, WithRetry(2))
+		_, err := client.GetRecord(context.Background(), "posts", "record-1")
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.Status != 503 {
+			t.Errorf("Expected 503 APIError, got %v", err)
+		}
+		if attempts != 3 {
+			t.Errorf("Expected 3 attempts, got %d", attempts)
+		}
+	})
+
+	t.Run("does not retry non-idempotent requests", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL, WithRetry(2))
+		if _, err := client.CreateRecord(context.Background(), "posts", Record{"title": "Test"}); err == nil {
+			t.Error("Expected error, got nil")
+		}
+		if attempts != 1 {
+			t.Errorf("Expected 1 attempt, got %d", attempts)
+		}
+	})
+}
+
+func TestWithRetryBudget(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		time.Sleep(40 * time.Millisecond)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL,
+		WithRetry(10),
+		WithBackoff(func(int) time.Duration { return 0 }),
+		WithRetryBudget(100*time.Millisecond))
+
+	_, err := client.GetRecord(context.Background(), "posts", "record-1")
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != 503 {
+		t.Errorf("Expected the last 503 APIError, got %v", err)
+	}
+	if attempts < 2 || attempts > 3 {
+		t.Errorf("Expected the budget to stop retries after 2 or 3 attempts, got %d", attempts)
+	}
+}
+
+func TestWithServerTimeOffset(t *testing.T) {
+	// Token that expires in 5 minutes according to the local clock
+	token := testToken(map[string]any{"exp": time.Now().Add(5 * time.Minute).Unix()})
+
+	client := NewClient("http://localhost:8090")
+	client.SetToken(token)
+	if client.IsTokenExpired() {
+		t.Error("Expected token to be valid without offset")
+	}
+
+	// The server clock is 10 minutes ahead, so the token has already expired there
+	client = NewClient("http://localhost:8090", WithServerTimeOffset(10*time.Minute))
+	client.SetToken(token)
+	if !client.IsTokenExpired() {
+		t.Error("Expected token to be expired with a server clock 10 minutes ahead")
+	}
+
+	// Token expired 5 minutes ago locally, but the server clock is 10 minutes behind
+	client = NewClient("http://localhost:8090", WithServerTimeOffset(-10*time.Minute))
+	client.SetToken(testToken(map[string]any{"exp": time.Now().Add(-5 * time.Minute).Unix()}))
+	if client.IsTokenExpired() {
+		t.Error("Expected token to be valid with a server clock 10 minutes behind")
+	}
+}
+
+func TestClient_IsAuthenticated(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    string
+		expected bool
+	}{
+		{"empty token", "", false},
+		{"valid token", testToken(map[string]any{"exp": time.Now().Add(time.Hour).Unix()}), true},
+		{"expired token", testToken(map[string]any{"exp": time.Now().Add(-time.Hour).Unix()}), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient("http://localhost:8090")
+			client.SetToken(tt.token)
+
+			if got := client.IsAuthenticated(); got != tt.expected {
+				t.Errorf("Expected IsAuthenticated %t, got %t", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestClient_AuthType(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Unix()
+	tests := []struct {
+		name       string
+		token      string
+		expected   string
+		expectedOK bool
+		authRecord Record
+	}{
+		{"empty token", "", "", false, nil},
+		{"unparseable token", "not-a-jwt", "", false, nil},
+		{"user token", testToken(map[string]any{"id": "u1", "type": "auth", "collectionId": "_pb_users_auth_", "exp": exp}), "users", true, nil},
+		{"superuser token", testToken(map[string]any{"id": "s1", "type": "auth", "collectionId": "pbc_3142635823", "exp": exp}), "_superusers", true, nil},
+		{"legacy admin token", testToken(map[string]any{"id": "a1", "type": "admin", "exp": exp}), "_superusers", true, nil},
+		{"legacy user token", testToken(map[string]any{"id": "u1", "type": "authRecord", "collectionId": "_pb_users_auth_", "exp": exp}), "users", true, nil},
+		{
+			"custom collection with auth record",
+			testToken(map[string]any{"id": "m1", "type": "auth", "collectionId": "pbc_999", "exp": exp}),
+			"members", true,
+			Record{"id": "m1", "collectionId": "pbc_999", "collectionName": "members"},
+		},
+		{"custom collection without auth record", testToken(map[string]any{"id": "m1", "type": "auth", "collectionId": "pbc_999", "exp": exp}), "pbc_999", true, nil},
+		{"file token", testToken(map[string]any{"id": "u1", "type": "file", "collectionId": "_pb_users_auth_", "exp": exp}), "", false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient("http://localhost:8090")
+			client.setAuth(tt.token, tt.authRecord)
+
+			collection, ok := client.AuthType()
+			if collection != tt.expected || ok != tt.expectedOK {
+				t.Errorf("Expected (%q, %t), got (%q, %t)", tt.expected, tt.expectedOK, collection, ok)
+			}
+		})
+	}
+}
+
+func TestWithBackoff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	var retries []int
+	client := NewClient(server.URL,
+		WithRetry(3),
+		WithBackoff(func(retry int) time.Duration {
+			retries = append(retries, retry)
+			return time.Millisecond
+		}))
+
+	if _, err := client.GetRecord(context.Background(), "posts", "record-1"); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	if fmt.Sprint(retries) != "[1 2 3]" {
+		t.Errorf("Expected backoff to be consulted for retries [1 2 3], got %v", retries)
+	}
+}
+
+func TestDefaultBackoff(t *testing.T) {
+	for _, retry := range []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 10, 37, 38, 64, 1000} {
+		ceiling := maxRetryBackoff
+		if retry <= 7 {
+			ceiling = min(minRetryBackoff<<max(retry-1, 0), maxRetryBackoff)
+		}
+		for range 20 {
+			if wait := DefaultBackoff(retry); wait < 0 || wait >= ceiling {
+				t.Fatalf("Expected backoff for retry %d in [0, %v), got %v", retry, ceiling, wait)
+			}
+		}
+	}
+}
+
+func TestRecord_ForUpdate(t *testing.T) {
+	record := Record{
+		"id":             "record-1",
+		"created":        "2024-01-01 10:00:00.000Z",
+		"updated":        "2024-01-02 10:00:00.000Z",
+		"collectionId":   "pbc_123",
+		"collectionName": "posts",
+		"expand":         map[string]any{"author": map[string]any{"id": "user-1"}},
+		"title":          "Post",
+		"status":         "draft",
+	}
+
+	update := record.ForUpdate()
+
+	for _, field := range []string{"id", "created", "updated", "collectionId", "collectionName", "expand"} {
+		if _, ok := update[field]; ok {
+			t.Errorf("Expected field '%s' to be stripped", field)
+		}
+	}
+	if update["title"] != "Post" || update["status"] != "draft" {
+		t.Errorf("Expected editable fields to be kept, got %v", update)
+	}
+	if record["id"] != "record-1" {
+		t.Error("Expected original record to be left untouched")
+	}
+}
+
+func TestClient_AuthWithOAuth2Interactive(t *testing.T) {
+	var redirectURL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/api/collections/users/auth-methods":
+			fmt.Fprint(w, `{"oauth2": {"enabled": true, "providers": [{
+				"name": "github",
+				"state": "state-123",
+				"authURL": "https://github.com/login/oauth/authorize?state=state-123&redirect_uri=",
+				"codeVerifier": "verifier-123"
+			}]}}`)
+		case "/api/collections/users/auth-with-oauth2":
+			var body map[string]any
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["code"] != "code-123" || body["codeVerifier"] != "verifier-123" || body["redirectURL"] != redirectURL {
+				t.Errorf("Unexpected auth-with-oauth2 body %v", body)
+			}
+			json.NewEncoder(w).Encode(authResp{Token: "oauth2-token", Record: Record{"id": "user-1"}})
+		default:
+			t.Errorf("Unexpected path '%s'", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := NewClient(server.URL)
+	record, err := client.AuthWithOAuth2Interactive(ctx, "users", "github",
+		WithOAuth2CallbackPath("/oauth2-redirect"),
+		WithOAuth2URLHandler(func(authURL string) error {
+			// Simulate the provider redirecting the browser back to the callback server
+			parsed, err := url.Parse(authURL)
+			if err != nil {
+				return err
+			}
+			redirectURL = parsed.Query().Get("redirect_uri")
+			if !strings.HasSuffix(redirectURL, "/oauth2-redirect") {
+				t.Errorf("Expected redirect URL with path '/oauth2-redirect', got '%s'", redirectURL)
+			}
+
+			go func() {
+				resp, err := http.Get(redirectURL + "?state=state-123&code=code-123")
+				if err != nil {
+					t.Errorf("Failed to call the callback server: %v", err)
+					return
+				}
+				resp.Body.Close()
+			}()
+			return nil
+		}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if record["id"] != "user-1" {
+		t.Errorf("Expected record ID 'user-1', got '%v'", record["id"])
+	}
+	if client.GetToken() != "oauth2-token" {
+		t.Errorf("Expected token 'oauth2-token', got '%s'", client.GetToken())
+	}
+}
+
+func TestValidationErrorsAs(t *testing.T) {
+	type signupErrors struct {
+		Email    *FieldError `json:"email"`
+		Password *FieldError `json:"password"`
+		Username *FieldError `json:"username"`
+	}
+
+	apiErr := &APIError{
+		Status:  400,
+		Message: "Failed to create record.",
+		Data: map[string]any{
+			"email":    map[string]any{"code": "validation_invalid_email", "message": "Must be a valid email address."},
+			"password": map[string]any{"code": "validation_length_out_of_range", "message": "The length must be between 8 and 72."},
+		},
+	}
+
+	errs, ok := ValidationErrorsAs[signupErrors](fmt.Errorf("signup: %w", apiErr))
+	if !ok {
+		t.Fatal("Expected validation errors to be decoded")
+	}
+	if errs.Email == nil || errs.Email.Code != "validation_invalid_email" {
+		t.Errorf("Expected email code 'validation_invalid_email', got %+v", errs.Email)
+	}
+	if errs.Password == nil || errs.Password.Message != "The length must be between 8 and 72." {
+		t.Errorf("Expected password message, got %+v", errs.Password)
+	}
+	if errs.Username != nil {
+		t.Errorf("Expected no username error, got %+v", errs.Username)
+	}
+
+	if _, ok := ValidationErrorsAs[signupErrors](errors.New("network error")); ok {
+		t.Error("Expected false for a non-API error")
+	}
+	if _, ok := ValidationErrorsAs[signupErrors](&APIError{Status: 404}); ok {
+		t.Error("Expected false for an API error without data")
+	}
+}
+
+func TestWithMinimalResponse(t *testing.T) {
+	// Mock server that honors the fields query parameter like PocketBase
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		record := Record{"id": "record-1", "title": "Event", "payload": strings.Repeat("x", 1024)}
+		if fields := r.URL.Query().Get("fields"); fields != "" {
+			if fields != "id" {
+				t.Errorf("Expected fields 'id', got '%s'", fields)
+			}
+			record = Record{"id": "record-1"}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(record)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	ctx := context.Background()
+
+	created, err := client.CreateRecord(ctx, "events", Record{"title": "Event"}, WithMinimalResponse(), WithFields("title"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(created) != 1 || created["id"] != "record-1" {
+		t.Errorf("Expected only the record ID, got %v", created)
+	}
+
+	updated, err := client.UpdateRecord(ctx, "events", "record-1", Record{"title": "Event"}, WithMinimalResponse())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(updated) != 1 || updated["id"] != "record-1" {
+		t.Errorf("Expected only the record ID, got %v", updated)
+	}
+}
+
+func TestWithCircuitBreaker(t *testing.T) {
+	var mu sync.Mutex
+	status := http.StatusServiceUnavailable
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if status != http.StatusOK {
+			json.NewEncoder(w).Encode(apiErrorResp{Status: status, Message: "Service unavailable."})
+			return
+		}
+		json.NewEncoder(w).Encode(Record{"id": "record-1"})
+	}))
+	defer server.Close()
+
+	setStatus := func(s int) {
+		mu.Lock()
+		defer mu.Unlock()
+		status = s
+	}
+	getAttempts := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return attempts
+	}
+
+	client := NewClient(server.URL, WithCircuitBreaker(2, 100*time.Millisecond))
+	ctx := context.Background()
+
+	// Closed: failures reach the server until the threshold
+	for range 2 {
+		if _, err := client.GetRecord(ctx, "posts", "record-1"); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Expected server error while closed, got %v", err)
+		}
+	}
+
+	// Open: requests fail fast without reaching the server
+	if _, err := client.GetRecord(ctx, "posts", "record-1"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if getAttempts() != 2 {
+		t.Errorf("Expected 2 requests to reach the server, got %d", getAttempts())
+	}
+
+	// Half-open: a failed trial request opens the circuit again
+	time.Sleep(150 * time.Millisecond)
+	if _, err := client.GetRecord(ctx, "posts", "record-1"); errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected trial request after cooldown, got %v", err)
+	}
+	if _, err := client.GetRecord(ctx, "posts", "record-1"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen after failed trial, got %v", err)
+	}
+
+	// Half-open: a successful trial request closes the circuit
+	setStatus(http.StatusOK)
+	time.Sleep(150 * time.Millisecond)
+	for range 3 {
+		if _, err := client.GetRecord(ctx, "posts", "record-1"); err != nil {
+			t.Fatalf("Expected no error after recovery, got %v", err)
+		}
+	}
+	if getAttempts() != 6 {
+		t.Errorf("Expected 6 requests to reach the server, got %d", getAttempts())
+	}
+}
+
+func TestWithCircuitBreaker_IgnoresClientErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithCircuitBreaker(1, time.Minute))
+
+	for range 3 {
+		if _, err := client.GetRecord(context.Background(), "posts", "missing"); errors.Is(err, ErrCircuitOpen) {
+			t.Fatal("Expected 404 responses not to open the circuit")
+		}
+	}
+}
+
+func TestWithCircuitBreaker_TrialWithoutOutcome(t *testing.T) {
+	var attempts atomic.Int32
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if err := r.Context().Err(); err != nil {
+			return nil, err
+		}
+		attempts.Add(1)
+		return &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"status": 503, "message": "Service unavailable."}`)),
+			Request:    r,
+		}, nil
+	})
+
+	client := NewClient("http://pb.example.com", WithCircuitBreaker(1, 50*time.Millisecond),
+		WithRequireHTTPSForAuth(), WithHTTPClient(&http.Client{Transport: transport}))
+
+	if _, err := client.GetRecord(context.Background(), "posts", "record-1"); errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected server error while closed, got %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	trials := []struct {
+		name string
+		ctx  context.Context
+		err  error
+	}{
+		{"canceled trial", canceled, context.Canceled},
+		{"trial never sent", ContextWithToken(context.Background(), "test-token"), ErrInsecureAuth},
+	}
+
+	for _, trial := range trials {
+		t.Run(trial.name, func(t *testing.T) {
+			if _, err := client.GetRecord(trial.ctx, "posts", "record-1"); !errors.Is(err, trial.err) {
+				t.Fatalf("Expected %v, got %v", trial.err, err)
+			}
+			if attempts.Load() != 1 {
+				t.Errorf("Expected the trial not to reach the server, got %d requests", attempts.Load())
+			}
+
+			// The breaker is open again, with the next request as the trial
+			if client.circuitBreaker.state != circuitOpen {
+				t.Errorf("Expected the circuit to be open, got state %d", client.circuitBreaker.state)
+			}
+			if client.circuitBreaker.failures != 1 {
+				t.Errorf("Expected the failure count to be kept, got %d", client.circuitBreaker.failures)
+			}
+		})
+	}
+
+	// The next trial reaches the server and its failure opens the circuit for the cooldown
+	if _, err := client.GetRecord(context.Background(), "posts", "record-1"); errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected a trial request, got %v", err)
+	}
+	if _, err := client.GetRecord(context.Background(), "posts", "record-1"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen after the failed trial, got %v", err)
+	}
+	if attempts.Load() != 2 {
+		t.Errorf("Expected 2 requests to reach the server, got %d", attempts.Load())
+	}
+}
+
+func TestRecord_SetJSONPath(t *testing.T) {
+	original := Record{
+		"meta": map[string]any{
+			"author": map[string]any{"name": "Bob", "age": 42.0},
+			"tags":   []any{"go"},
+		},
+	}
+	record := maps.Clone(original)
+
+	record.SetJSONPath("meta", "author.name", "Alice")
+	record.SetJSONPath("meta", "stats.views.total", 10)
+	record.SetJSONPath("settings", "theme", "dark")
+
+	data, _ := json.Marshal(record)
+	expected := `{"meta":{"author":{"age":42,"name":"Alice"},"stats":{"views":{"total":10}},"tags":["go"]},"settings":{"theme":"dark"}}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	// Nested maps shared with the original record are not modified
+	author := original["meta"].(map[string]any)["author"].(map[string]any)
+	if author["name"] != "Bob" {
+		t.Errorf("Expected original record to keep 'Bob', got '%v'", author["name"])
+	}
+	if len(Diff(original, record)) != 2 {
+		t.Errorf("Expected 2 changed fields, got %v", Diff(original, record))
+	}
+}
+
+func TestJSONPath(t *testing.T) {
+	if path := JSONPath("meta", "author", "name"); path != "meta.author.name" {
+		t.Errorf("Expected 'meta.author.name', got '%s'", path)
+	}
+	if path := JSONPath("meta"); path != "meta" {
+		t.Errorf("Expected 'meta', got '%s'", path)
+	}
+
+	filter := Filter(JSONPath("meta", "tags", "0")+" = {:tag}", map[string]any{"tag": "go"})
+	if filter != "meta.tags.0 = 'go'" {
+		t.Errorf("Expected \"meta.tags.0 = 'go'\", got \"%s\"", filter)
+	}
+}
+
+func TestClient_WarmUp(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/health" {
+			t.Errorf("Expected path '/api/health', got '%s'", r.URL.Path)
+		}
+		mu.Lock()
+		requests++
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"code": 200, "message": "API is healthy.", "data": {}}`)
+	}))
+	defer server.Close()
+
+	t.Run("sends a single health request", func(t *testing.T) {
+		client := NewClient(server.URL)
+		if err := client.WarmUp(context.Background()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if requests != 1 {
+			t.Errorf("Expected 1 health request, got %d", requests)
+		}
+		requests = 0
+	})
+
+	t.Run("keep-alive pings periodically", func(t *testing.T) {
+		client := NewClient(server.URL, WithKeepAlivePing(20*time.Millisecond))
+		defer client.stopBackground()
+
+		time.Sleep(110 * time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if requests < 2 {
+			t.Errorf("Expected at least 2 keep-alive pings, got %d", requests)
+		}
+	})
+}
+
+func TestGetListAs(t *testing.T) {
+	type post struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+		Views int    `json:"views"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("page") != "2" || query.Get("perPage") != "2" {
+			t.Errorf("Expected page 2 with perPage 2, got page %s with perPage %s", query.Get("page"), query.Get("perPage"))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"page": 2,
+			"perPage": 2,
+			"totalItems": 5,
+			"totalPages": 3,
+			"items": [
+				{"id": "post-3", "title": "Third", "views": 30},
+				{"id": "post-4", "title": "Fourth", "views": 40}
+			]
+		}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	result, err := GetListAs[post](context.Background(), client, "posts", 2, 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.Page != 2 || result.PerPage != 2 || result.TotalItems != 5 || result.TotalPages != 3 {
+		t.Errorf("Expected pagination 2/2/5/3, got %d/%d/%d/%d", result.Page, result.PerPage, result.TotalItems, result.TotalPages)
+	}
+	if len(result.Items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(result.Items))
+	}
+	if result.Items[1] != (post{ID: "post-4", Title: "Fourth", Views: 40}) {
+		t.Errorf("Expected decoded post-4, got %+v", result.Items[1])
+	}
+
+	list, err := client.GetList(context.Background(), "posts", 2, 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if list.TotalItems != 5 || list.Items[0]["title"] != "Third" {
+		t.Errorf("Expected record items with totals, got %+v", list)
+	}
+}
+
+func TestWithStrictDecoding(t *testing.T) {
+	type post struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+	}
+
+	// Mock server returning a field the post type doesn't declare
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"page": 1, "perPage": 30, "totalItems": 1, "totalPages": 1,
+			"items": [{"id": "post-1", "title": "First", "subtitle": "New field"}]}`)
+	}))
+	defer server.Close()
+
+	t.Run("lenient by default", func(t *testing.T) {
+		client := NewClient(server.URL)
+		result, err := GetListAs[post](context.Background(), client, "posts", 1, 30)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result.Items[0].Title != "First" {
+			t.Errorf("Expected title 'First', got '%s'", result.Items[0].Title)
+		}
+	})
+
+	t.Run("strict mode rejects unknown fields", func(t *testing.T) {
+		client := NewClient(server.URL, WithStrictDecoding())
+		_, err := GetListAs[post](context.Background(), client, "posts", 1, 30)
+		if err == nil || !strings.Contains(err.Error(), "subtitle") {
+			t.Errorf("Expected unknown field error mentioning 'subtitle', got %v", err)
+		}
+
+		// Untyped records are not affected
+		if _, err := client.GetList(context.Background(), "posts", 1, 30); err != nil {
+			t.Errorf("Expected no error for untyped records, got %v", err)
+		}
+	})
+}
+
+func TestClient_GetRecordsByIDs(t *testing.T) {
+	idPattern := regexp.MustCompile(`id = '([^']+)'`)
+
+	var requests atomic.Int32
+	var filters []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		filter := r.URL.Query().Get("filter")
+		filters = append(filters, filter)
+
+		var items []Record
+		for _, match := range idPattern.FindAllStringSubmatch(filter, -1) {
+			items = append(items, Record{"id": match[1]})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"page": 1, "perPage": len(items), "totalItems": len(items), "totalPages": 1, "items": items,
+		})
+	}))
+	defer server.Close()
+
+	ids := make([]string, 120)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("id%03d", i)
+	}
+
+	tests := []struct {
+		name             string
+		opts             []ListOption
+		expectedRequests int
+	}{
+		{"default chunk size", nil, 3},
+		{"custom chunk size", []ListOption{WithChunkSize(25)}, 5},
+		{"single chunk", []ListOption{WithChunkSize(200)}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests.Store(0)
+			client := NewClient(server.URL)
+
+			records, err := client.GetRecordsByIDs(context.Background(), "users", ids, tt.opts...)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if int(requests.Load()) != tt.expectedRequests {
+				t.Errorf("Expected %d requests, got %d", tt.expectedRequests, requests.Load())
+			}
+			if len(records) != len(ids) {
+				t.Fatalf("Expected %d records, got %d", len(ids), len(records))
+			}
+			for i, record := range records {
+				if record["id"] != ids[i] {
+					t.Fatalf("Expected record %s at index %d, got %v", ids[i], i, record["id"])
+				}
+			}
+		})
+	}
+
+	t.Run("filter is combined with every chunk", func(t *testing.T) {
+		filters = nil
+		client := NewClient(server.URL)
+
+		_, err := client.GetRecordsByIDs(context.Background(), "users", ids[:4], WithChunkSize(2), WithFilter("verified = true"))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		expected := []string{
+			"(verified = true) && (id = 'id000' || id = 'id001')",
+			"(verified = true) && (id = 'id002' || id = 'id003')",
+		}
+		if !slices.Equal(filters, expected) {
+			t.Errorf("Expected filters %q, got %q", expected, filters)
+		}
+	})
+
+	t.Run("no ids sends no request", func(t *testing.T) {
+		requests.Store(0)
+		client := NewClient(server.URL)
+
+		records, err := client.GetRecordsByIDs(context.Background(), "users", nil)
+		if err != nil || len(records) != 0 || requests.Load() != 0 {
+			t.Errorf("Expected no records and no request, got %v, %v and %d requests", records, err, requests.Load())
+		}
+	})
+}
+
+func TestWithRecordHook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/collections/users/records":
+			json.NewEncoder(w).Encode(map[string]any{
+				"page": 1, "perPage": 30, "totalItems": 2, "totalPages": 1,
+				"items": []Record{
+					{"id": "u1", "firstName": "Ada", "lastName": "Lovelace"},
+					{"id": "u2", "firstName": "Alan", "lastName": "Turing"},
+				},
+			})
+		case r.Method == "GET":
+			json.NewEncoder(w).Encode(Record{"id": "u1", "firstName": "Ada", "lastName": "Lovelace"})
+		case r.URL.Path == "/api/collections/users/auth-refresh":
+			json.NewEncoder(w).Encode(authResp{Token: "refreshed-token", Record: Record{"id": "u1", "firstName": "Ada", "lastName": "Lovelace"}})
+		case strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data"):
+			json.NewEncoder(w).Encode(Record{"id": "u4", "firstName": r.FormValue("firstName"), "lastName": r.FormValue("lastName")})
+		default:
+			var body Record
+			json.NewDecoder(r.Body).Decode(&body)
+			body["id"] = "u3"
+			json.NewEncoder(w).Encode(body)
+		}
+	}))
+	defer server.Close()
+
+	calls := 0
+	client := NewClient(server.URL, WithRecordHook(func(record Record) Record {
+		calls++
+		record["fullName"] = fmt.Sprintf("%v %v", record["firstName"], record["lastName"])
+		return record
+	}))
+	ctx := context.Background()
+
+	t.Run("GetRecord", func(t *testing.T) {
+		record, err := client.GetRecord(ctx, "users", "u1")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if record["fullName"] != "Ada Lovelace" {
+			t.Errorf("Expected computed fullName 'Ada Lovelace', got %v", record["fullName"])
+		}
+	})
+
+	t.Run("GetAllRecords", func(t *testing.T) {
+		records, err := client.GetAllRecords(ctx, "users")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(records) != 2 || records[0]["fullName"] != "Ada Lovelace" || records[1]["fullName"] != "Alan Turing" {
+			t.Errorf("Expected computed fullName on every record, got %v", records)
+		}
+	})
+
+	t.Run("GetAllRecords with page callback", func(t *testing.T) {
+		var names []any
+		_, err := client.GetAllRecords(ctx, "users", WithPageCallback(func(items []Record) error {
+			for _, item := range items {
+				names = append(names, item["fullName"])
+			}
+			return nil
+		}))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !slices.Equal(names, []any{"Ada Lovelace", "Alan Turing"}) {
+			t.Errorf("Expected the callback to receive hooked records, got %v", names)
+		}
+	})
+
+	t.Run("CreateRecord and UpdateRecord", func(t *testing.T) {
+		created, err := client.CreateRecord(ctx, "users", Record{"firstName": "Grace", "lastName": "Hopper"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if created["fullName"] != "Grace Hopper" {
+			t.Errorf("Expected computed fullName on the created record, got %v", created["fullName"])
+		}
+
+		updated, err := client.UpdateRecord(ctx, "users", "u3", Record{"firstName": "Anita", "lastName": "Borg"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if updated["fullName"] != "Anita Borg" {
+			t.Errorf("Expected computed fullName on the updated record, got %v", updated["fullName"])
+		}
+	})
+
+	t.Run("single page lookups", func(t *testing.T) {
+		first, err := client.GetFirstListItem(ctx, "users", "firstName = 'Ada'")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if first["fullName"] != "Ada Lovelace" {
+			t.Errorf("Expected computed fullName from GetFirstListItem, got %v", first["fullName"])
+		}
+
+		records, err := client.GetRecords(ctx, "users", 2)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(records) != 2 || records[1]["fullName"] != "Alan Turing" {
+			t.Errorf("Expected computed fullName from GetRecords, got %v", records)
+		}
+
+		list, err := client.GetList(ctx, "users", 1, 30)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(list.Items) != 2 || list.Items[0]["fullName"] != "Ada Lovelace" {
+			t.Errorf("Expected computed fullName from GetList, got %v", list.Items)
+		}
+	})
+
+	t.Run("file uploads", func(t *testing.T) {
+		created, err := client.CreateRecordWithFiles(ctx, "users",
+			WithFormData(Record{"firstName": "Grace", "lastName": "Hopper"}),
+			WithFileUpload("avatar", []FileData{CreateFileDataFromBytes([]byte("png"), "avatar.png")}))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if created["fullName"] != "Grace Hopper" {
+			t.Errorf("Expected computed fullName on the uploaded record, got %v", created["fullName"])
+		}
+	})
+
+	t.Run("auth records", func(t *testing.T) {
+		record, err := client.AuthRefresh(ctx, "users")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if record["fullName"] != "Ada Lovelace" || client.AuthRecord()["fullName"] != "Ada Lovelace" {
+			t.Errorf("Expected computed fullName on the returned and stored auth record, got %v and %v", record, client.AuthRecord())
+		}
+	})
+
+	t.Run("not applied on errors", func(t *testing.T) {
+		calls = 0
+		failing := NewClient("http://127.0.0.1:1", WithRecordHook(func(record Record) Record {
+			calls++
+			return record
+		}))
+		if _, err := failing.GetRecord(ctx, "users", "u1"); err == nil {
+			t.Fatal("Expected an error")
+		}
+		if calls != 0 {
+			t.Errorf("Expected the hook not to run, got %d calls", calls)
+		}
+	})
+}
+
+func TestClient_ResolveRelationDeep(t *testing.T) {
+	// comments.post -> posts, posts.author -> users, users.pinned -> posts (circular)
+	collections := map[string][]Record{
+		"posts": {
+			{"id": "post-1", "collectionName": "posts", "title": "First", "author": "user-1"},
+		},
+		"users": {
+			{"id": "user-1", "collectionName": "users", "name": "Alice", "pinned": []any{"post-1"}},
+		},
+	}
+
+	var mu sync.Mutex
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		collection := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/collections/"), "/records")
+
+		mu.Lock()
+		requests[collection]++
+		mu.Unlock()
+
+		var items []Record
+		for _, record := range collections[collection] {
+			if strings.Contains(r.URL.Query().Get("filter"), record["id"].(string)) {
+				items = append(items, record)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(listResp{Page: 1, PerPage: 50, TotalItems: len(items), TotalPages: 1, Items: items})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	comments := []Record{
+		{"id": "comment-1", "collectionName": "comments", "post": "post-1"},
+		{"id": "comment-2", "collectionName": "comments", "post": "post-1"},
+	}
+	specs := []RelationSpec{
+		{Collection: "comments", Field: "post", Target: "posts"},
+		{Collection: "posts", Field: "author", Target: "users"},
+		{Collection: "users", Field: "pinned", Target: "posts"},
+	}
+
+	resolved, err := client.ResolveRelationDeep(context.Background(), comments, specs, 3)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, comment := range resolved {
+		author := comment.Expand("post").Expand("author")
+		if author["name"] != "Alice" {
+			t.Errorf("Expected author 'Alice' for %s, got %v", comment["id"], author)
+		}
+
+		// The circular relation is expanded until the max depth and then stops
+		pinned := author.ExpandList("pinned")
+		if len(pinned) != 1 || pinned[0]["title"] != "First" {
+			t.Errorf("Expected pinned post 'First', got %v", pinned)
+		}
+		if pinned[0]["expand"] != nil {
+			t.Errorf("Expected expansion to stop at max depth, got %v", pinned[0]["expand"])
+		}
+	}
+
+	// Each related record is fetched only once
+	if requests["posts"] != 1 || requests["users"] != 1 {
+		t.Errorf("Expected 1 request per collection, got %v", requests)
+	}
+
+	// The given records are not modified
+	if comments[0]["expand"] != nil {
+		t.Error("Expected original records to be left untouched")
+	}
+
+	// The result can be encoded, so it contains no cycles
+	if _, err := json.Marshal(resolved); err != nil {
+		t.Errorf("Expected resolved records to be encodable, got %v", err)
+	}
+}
+
+func TestWithProxy(t *testing.T) {
+	t.Run("routes requests through the proxy", func(t *testing.T) {
+		// Stub proxy answering on behalf of the target server
+		var proxied []string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxied = append(proxied, r.URL.String())
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(Record{"id": "record-1"})
+		}))
+		defer proxy.Close()
+
+		client := NewClient("http://pocketbase.internal:8090", WithProxy(proxy.URL), WithTimeout(5*time.Second))
+
+		record, err := client.GetRecord(context.Background(), "posts", "record-1")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if record["id"] != "record-1" {
+			t.Errorf("Expected record ID 'record-1', got '%v'", record["id"])
+		}
+
+		expected := "http://pocketbase.internal:8090/api/collections/posts/records/record-1"
+		if len(proxied) != 1 || proxied[0] != expected {
+			t.Errorf("Expected proxied request to %s, got %v", expected, proxied)
+		}
+	})
+
+	t.Run("invalid proxy URL", func(t *testing.T) {
+		client := NewClient("http://localhost:8090", WithProxy("://bad"))
+
+		_, err := client.GetRecord(context.Background(), "posts", "record-1")
+		if err == nil || !strings.Contains(err.Error(), "invalid proxy URL") {
+			t.Errorf("Expected invalid proxy URL error, got %v", err)
+		}
+	})
+}
+
+func TestWithSharedSuperuserAuth(t *testing.T) {
+	var mu sync.Mutex
+	logins := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/collections/_superusers/auth-with-password" {
+			t.Errorf("Unexpected path '%s'", r.URL.Path)
+		}
+
+		mu.Lock()
+		logins++
+		// Hand out tokens expiring in an hour, except the first one which is about to expire
+		exp := time.Now().Add(time.Hour)
+		if logins == 1 {
+			exp = time.Now().Add(30 * time.Second)
+		}
+		token := testToken(map[string]any{"exp": exp.Unix(), "login": logins})
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(authResp{Token: token, Record: Record{"id": "superuser-1"}})
+	}))
+	defer server.Close()
+
+	store := NewSuperuserTokenStore()
+	ctx := context.Background()
+
+	newClient := func() *Client {
+		return NewClient(server.URL, WithSharedSuperuserAuth(store))
+	}
+
+	// A token about to expire is replaced once
+	first := newClient()
+	if _, err := first.AuthenticateAsSuperuser(ctx, "admin@example.com", "password"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	clients := []*Client{newClient(), newClient(), newClient()}
+	var wg sync.WaitGroup
+	for _, client := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.AuthenticateAsSuperuser(ctx, "admin@example.com", "password"); err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if logins != 2 {
+		t.Errorf("Expected 2 logins, got %d", logins)
+	}
+	for _, client := range clients[1:] {
+		if client.GetToken() != clients[0].GetToken() {
+			t.Error("Expected clients to share the same token")
+		}
+	}
+	if clients[0].GetToken() == first.GetToken() {
+		t.Error("Expected the expiring token to be replaced")
+	}
+
+	// Different credentials never share a token
+	other := newClient()
+	if _, err := other.AuthenticateAsSuperuser(ctx, "admin@example.com", "other-password"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if logins != 3 {
+		t.Errorf("Expected a separate login for different credentials, got %d logins", logins)
+	}
+}
+
+func TestClient_ValidateFilter(t *testing.T) {
+	// Mock server rejecting filters with unbalanced quotes like PocketBase
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("perPage") != "1" || query.Get("skipTotal") != "1" {
+			t.Errorf("Expected a minimal probe, got %s", r.URL.RawQuery)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Count(query.Get("filter"), "'")%2 != 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(apiErrorResp{Status: 400, Message: "Something went wrong while processing your request. Invalid filter parameters."})
+			return
+		}
+		json.NewEncoder(w).Encode(listResp{Page: 1, PerPage: 1, Items: []Record{}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	ctx := context.Background()
+
+	if err := client.ValidateFilter(ctx, "posts", "status = 'published'"); err != nil {
+		t.Errorf("Expected valid filter, got %v", err)
+	}
+
+	err := client.ValidateFilter(ctx, "posts", "status = 'published")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !apiErr.IsBadRequest() {
+		t.Errorf("Expected 400 APIError, got %v", err)
+	}
+}
+
+func TestClient_DownloadFile(t *testing.T) {
+	content := "Hello, PocketBase!"
+
+	// Mock server honoring range requests like PocketBase
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/files/documents/abc123/hello.txt" {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(apiErrorResp{Status: 404, Message: "The requested resource wasn't found."})
+			return
+		}
+		http.ServeContent(w, r, "hello.txt", time.Time{}, strings.NewReader(content))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	ctx := context.Background()
+
+	download := func(t *testing.T, opts ...DownloadOption) string {
+		body, err := client.DownloadFile(ctx, "documents", "abc123", "hello.txt", opts...)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		defer body.Close()
+
+		data, err := io.ReadAll(body)
+		if err != nil {
+			t.Fatalf("Expected no read error, got %v", err)
+		}
+		return string(data)
+	}
+
+	t.Run("whole file", func(t *testing.T) {
+		if got := download(t); got != content {
+			t.Errorf("Expected %q, got %q", content, got)
+		}
+	})
+
+	t.Run("range", func(t *testing.T) {
+		if got := download(t, WithRange(7, 16)); got != "PocketBase" {
+			t.Errorf("Expected %q, got %q", "PocketBase", got)
+		}
+	})
+
+	t.Run("open-ended range", func(t *testing.T) {
+		if got := download(t, WithRange(7, -1)); got != "PocketBase!" {
+			t.Errorf("Expected %q, got %q", "PocketBase!", got)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := client.DownloadFile(ctx, "documents", "abc123", "missing.txt")
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || !apiErr.IsNotFound() {
+			t.Errorf("Expected 404 APIError, got %v", err)
+		}
+	})
+}
+
+func TestClient_DownloadFile_RangeIgnored(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("whole file"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	if _, err := client.DownloadFile(context.Background(), "documents", "abc123", "hello.txt", WithRange(5, -1)); err == nil {
+		t.Error("Expected an error when the server ignores the range")
+	}
+}
+
+func TestWithDefaultListOptions(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(listResp{Page: 1, TotalPages: 1})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithDefaultListOptions(WithFilter("deleted = false"), WithSort("-created")))
+	ctx := context.Background()
+
+	t.Run("defaults are used without per-call options", func(t *testing.T) {
+		if _, err := client.GetAllRecords(ctx, "posts"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if query.Get("filter") != "deleted = false" {
+			t.Errorf("Expected default filter, got '%s'", query.Get("filter"))
+		}
+		if query.Get("sort") != "-created" {
+			t.Errorf("Expected default sort, got '%s'", query.Get("sort"))
+		}
+	})
+
+	t.Run("per-call filter is combined with the default", func(t *testing.T) {
+		if _, err := client.GetList(ctx, "posts", 1, 10, WithFilter("status = 'published'"), WithSort("title")); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		expected := "(deleted = false) && (status = 'published')"
+		if query.Get("filter") != expected {
+			t.Errorf("Expected filter '%s', got '%s'", expected, query.Get("filter"))
+		}
+		if query.Get("sort") != "title" {
+			t.Errorf("Expected per-call sort to override the default, got '%s'", query.Get("sort"))
+		}
+	})
+
+	t.Run("logs are not scoped", func(t *testing.T) {
+		if _, err := client.GetLogs(ctx); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if query.Get("filter") != "" {
+			t.Errorf("Expected no filter on logs, got '%s'", query.Get("filter"))
+		}
+	})
+}
+
+func TestClient_ServerVersion(t *testing.T) {
+	newServer := func(header, version string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/health" {
+				t.Errorf("Expected health endpoint, got %s", r.URL.Path)
+			}
+			if header != "" {
+				w.Header().Set("X-PocketBase-Version", header)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			data := map[string]any{"canBackup": true}
+			if version != "" {
+				data["version"] = version
+			}
+			json.NewEncoder(w).Encode(map[string]any{"message": "API is healthy.", "code": 200, "data": data})
+		}))
+	}
+
+	tests := []struct {
+		name     string
+		header   string
+		payload  string
+		expected string
+	}{
+		{name: "from header", header: "0.28.1", expected: "0.28.1"},
+		{name: "from health payload", payload: "0.27.2", expected: "0.27.2"},
+		{name: "header takes precedence", header: "0.28.1", payload: "0.27.2", expected: "0.28.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newServer(tt.header, tt.payload)
+			defer server.Close()
+
+			version, err := NewClient(server.URL).ServerVersion(context.Background())
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if version != tt.expected {
+				t.Errorf("Expected version %s, got %s", tt.expected, version)
+			}
+		})
+	}
+
+	t.Run("unknown", func(t *testing.T) {
+		server := newServer("", "")
+		defer server.Close()
+
+		_, err := NewClient(server.URL).ServerVersion(context.Background())
+		if !errors.Is(err, ErrUnknownVersion) {
+			t.Errorf("Expected ErrUnknownVersion, got %v", err)
+		}
+	})
+}
+
+func TestBatchBuilder_FileUploads(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/batch" {
+			t.Errorf("Expected batch endpoint, got %s", r.URL.Path)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("Expected multipart body, got %v", err)
+		}
+
+		var payload struct {
+			Requests []struct {
+				Method string         `json:"method"`
+				URL    string         `json:"url"`
+				Body   map[string]any `json:"body"`
+			} `json:"requests"`
+		}
+		if err := json.Unmarshal([]byte(r.FormValue("@jsonPayload")), &payload); err != nil {
+			t.Fatalf("Expected JSON payload, got %v", err)
+		}
+		if len(payload.Requests) != 3 {
+			t.Fatalf("Expected 3 requests, got %d", len(payload.Requests))
+		}
+		if payload.Requests[1].Method != "PATCH" || payload.Requests[1].URL != "/api/collections/documents/records/doc1" {
+			t.Errorf("Unexpected update request %+v", payload.Requests[1])
+		}
+		if deleted, _ := payload.Requests[1].Body["files-"].([]any); len(deleted) != 1 || deleted[0] != "old.pdf" {
+			t.Errorf("Expected file deletion in the JSON body, got %v", payload.Requests[1].Body)
+		}
+
+		expectedParts := map[string]string{
+			"requests.0.avatar": "avatar.png",
+			"requests.1.files+": "new.pdf",
+		}
+		if len(r.MultipartForm.File) != len(expectedParts) {
+			t.Errorf("Expected %d file parts, got %v", len(expectedParts), r.MultipartForm.File)
+		}
+		for part, filename := range expectedParts {
+			files := r.MultipartForm.File[part]
+			if len(files) != 1 || files[0].Filename != filename {
+				t.Errorf("Expected file %s in part %s, got %v", filename, part, files)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]BatchResult{
+			{Status: 200, Body: Record{"id": "user1"}},
+			{Status: 200, Body: Record{"id": "doc1"}},
+			{Status: 204},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	results, err := client.NewBatch().
+		Create("users", Record{"name": "Alice"},
+			WithBatchFileUpload("avatar", []FileData{CreateFileDataFromBytes([]byte("png"), "avatar.png")})).
+		Update("documents", "doc1", Record{"title": "Report"},
+			WithBatchFileUpload("files", []FileData{CreateFileDataFromBytes([]byte("pdf"), "new.pdf")}, WithAppend()),
+			WithBatchFileUpload("files", nil, WithDelete("old.pdf"))).
+		Delete("invites", "inv1").
+		Send(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(results) != 3 || results[0].Body["id"] != "user1" || results[2].Body != nil {
+		t.Errorf("Unexpected batch results %+v", results)
+	}
+}
+
+func TestBatchBuilder_JSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected JSON body without files, got %s", r.Header.Get("Content-Type"))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]BatchResult{{Status: 200, Body: Record{"id": "post1"}}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	results, err := client.NewBatch().Create("posts", Record{"title": "Hello"}).Send(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(results) != 1 || results[0].Status != 200 {
+		t.Errorf("Unexpected batch results %+v", results)
+	}
+
+	if _, err := client.NewBatch().Send(context.Background()); err == nil {
+		t.Error("Expected an error for an empty batch")
+	}
+}
+
+func TestClient_NewDeadlineBudget(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "slow") {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Record{"id": "abc123"})
+	}))
+	defer server.Close()
+
+	var logs strings.Builder
+	client := NewClient(server.URL, WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 400*time.Millisecond)
+	defer cancel()
+
+	budget := client.NewDeadlineBudget(ctx, 2)
+
+	callCtx, done := budget.Next()
+	_, err := client.GetRecord(callCtx, "slow", "abc123")
+	done()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the slow call to exceed its share, got %v", err)
+	}
+	if !strings.Contains(logs.String(), "deadline budget") {
+		t.Errorf("Expected a warning for the slow call, got %q", logs.String())
+	}
+
+	remaining, ok := budget.Remaining()
+	if !ok || remaining < 100*time.Millisecond {
+		t.Errorf("Expected about half of the budget left for the last call, got %v", remaining)
+	}
+
+	callCtx, done = budget.Next()
+	_, err = client.GetRecord(callCtx, "posts", "abc123")
+	done()
+	if err != nil {
+		t.Errorf("Expected the last call to succeed, got %v", err)
+	}
+}
+
+func TestClient_DeleteRecord(t *testing.T) {
+	t.Run("204 returns nil record", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != "DELETE" || r.URL.Path != "/api/collections/posts/records/abc123" {
+				t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		deleted, err := NewClient(server.URL).DeleteRecord(context.Background(), "posts", "abc123")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if deleted != nil {
+			t.Errorf("Expected nil record, got %v", deleted)
+		}
+	})
+
+	t.Run("200 returns deleted record", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("expand") != "author" {
+				t.Errorf("Expected expand parameter 'author', got '%s'", r.URL.Query().Get("expand"))
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(Record{"id": "abc123", "title": "Deleted post"})
+		}))
+		defer server.Close()
+
+		deleted, err := NewClient(server.URL).DeleteRecord(context.Background(), "posts", "abc123", WithExpand("author"))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if deleted["title"] != "Deleted post" {
+			t.Errorf("Expected deleted record, got %v", deleted)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(apiErrorResp{Status: 404, Message: "The requested resource wasn't found."})
+		}))
+		defer server.Close()
+
+		_, err := NewClient(server.URL).DeleteRecord(context.Background(), "posts", "missing")
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || !apiErr.IsNotFound() {
+			t.Errorf("Expected 404 APIError, got %v", err)
+		}
+	})
+}
+
+func TestWithHeaders(t *testing.T) {
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Record{"id": "abc123"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL,
+		WithHeader("X-Request-Source", "worker"),
+		WithHeaders(Headers{"X-Tenant": "acme", "x-region": "eu-west-1", "Content-Type": "text/plain"}))
+
+	if _, err := client.GetRecord(context.Background(), "posts", "abc123"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]string{
+		"X-Request-Source": "worker",
+		"X-Tenant":         "acme",
+		"X-Region":         "eu-west-1",
+		"Content-Type":     "application/json",
+	}
+	for name, value := range expected {
+		if headers.Get(name) != value {
+			t.Errorf("Expected header %s '%s', got '%s'", name, value, headers.Get(name))
+		}
+	}
+}
+
+func TestHeadersFromStruct(t *testing.T) {
+	type gatewayHeaders struct {
+		Tenant   string `header:"X-Tenant"`
+		Region   string `header:"X-Region,omitempty"`
+		Version  int    `header:"X-Api-Version"`
+		Debug    bool   `header:"X-Debug,omitempty"`
+		Internal string
+		ignored  string `header:"X-Ignored"`
+	}
+
+	headers, err := HeadersFromStruct(&gatewayHeaders{Tenant: "acme", Version: 2, Internal: "x", ignored: "y"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := Headers{"X-Tenant": "acme", "X-Api-Version": "2"}
+	if !maps.Equal(headers, expected) {
+		t.Errorf("Expected headers %v, got %v", expected, headers)
+	}
+
+	if _, err := HeadersFromStruct("not a struct"); err == nil {
+		t.Error("Expected an error for a non-struct value")
+	}
+}
+
+func TestClient_GetAllRecordsGroupedBy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := listResp{
+			Page:       1,
+			PerPage:    30,
+			TotalItems: 4,
+			TotalPages: 1,
+			Items: []Record{
+				{"id": "1", "category": "news"},
+				{"id": "2", "category": "sports"},
+				{"id": "3", "category": "news"},
+				{"id": "4"},
+			},
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	groups, err := client.GetAllRecordsGroupedBy(context.Background(), "posts", "category")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string][]string{"news": {"1", "3"}, "sports": {"2"}, "": {"4"}}
+	if len(groups) != len(expected) {
+		t.Errorf("Expected %d groups, got %d: %v", len(expected), len(groups), groups)
+	}
+	for key, ids := range expected {
+		var got []string
+		for _, record := range groups[key] {
+			got = append(got, record["id"].(string))
+		}
+		if !slices.Equal(got, ids) {
+			t.Errorf("Expected records %v in group '%s', got %v", ids, key, got)
+		}
+	}
+}
+
+func TestWithRequireHTTPSForAuth(t *testing.T) {
+	var authHeader string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader = r.Header.Get("Authorization")
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Record{"id": "abc123"})
+	})
+
+	t.Run("https sends the token", func(t *testing.T) {
+		server := httptest.NewTLSServer(handler)
+		defer server.Close()
+
+		client := NewClient(server.URL, WithHTTPClient(server.Client()), WithRequireHTTPSForAuth())
+		client.SetToken("test-token")
+
+		if _, err := client.GetRecord(context.Background(), "posts", "abc123"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if authHeader != "test-token" {
+			t.Errorf("Expected token to be sent, got '%s'", authHeader)
+		}
+	})
+
+	t.Run("localhost is exempted", func(t *testing.T) {
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		client := NewClient(strings.Replace(server.URL, "127.0.0.1", "localhost", 1), WithRequireHTTPSForAuth())
+		client.SetToken("test-token")
+
+		if _, err := client.GetRecord(context.Background(), "posts", "abc123"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if authHeader != "test-token" {
+			t.Errorf("Expected token to be sent, got '%s'", authHeader)
+		}
+	})
+
+	t.Run("remote http is refused", func(t *testing.T) {
+		requests := 0
+		client := NewClient("http://pb.example.com", WithRequireHTTPSForAuth(),
+			WithHTTPClient(&http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				requests++
+				return nil, errors.New("unexpected request")
+			})}))
+		client.SetToken("test-token")
+
+		_, err := client.GetRecord(context.Background(), "posts", "abc123")
+		if !errors.Is(err, ErrInsecureAuth) {
+			t.Errorf("Expected ErrInsecureAuth, got %v", err)
+		}
+		if requests != 0 {
+			t.Errorf("Expected no request to be sent, got %d", requests)
+		}
+	})
+
+	t.Run("loopback addresses are localhost", func(t *testing.T) {
+		if !isLocalhost("::1") || !isLocalhost("127.0.0.1") || isLocalhost("pb.example.com") {
+			t.Error("Unexpected localhost detection")
+		}
+	})
+}
+
+func TestWithSkipTLSForLocalhost(t *testing.T) {
+	var authHeader string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader = r.Header.Get("Authorization")
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Record{"id": "abc123"})
+	})
+
+	t.Run("self-signed localhost certificate is accepted", func(t *testing.T) {
+		server := httptest.NewTLSServer(handler)
+		defer server.Close()
+
+		for _, baseURL := range []string{server.URL, strings.Replace(server.URL, "127.0.0.1", "localhost", 1)} {
+			client := NewClient(baseURL, WithSkipTLSForLocalhost())
+			client.SetToken("test-token")
+
+			if _, err := client.GetRecord(context.Background(), "posts", "abc123"); err != nil {
+				t.Fatalf("Expected no error for %s, got %v", baseURL, err)
+			}
+			if authHeader != "test-token" {
+				t.Errorf("Expected token to be sent, got '%s'", authHeader)
+			}
+		}
+	})
+
+	t.Run("plain http localhost sends the token", func(t *testing.T) {
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		client := NewClient(server.URL, WithSkipTLSForLocalhost())
+		client.SetToken("test-token")
+
+		if _, err := client.GetRecord(context.Background(), "posts", "abc123"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if authHeader != "test-token" {
+			t.Errorf("Expected token to be sent, got '%s'", authHeader)
+		}
+	})
+
+	t.Run("public host certificate is verified", func(t *testing.T) {
+		server := httptest.NewTLSServer(handler)
+		defer server.Close()
+
+		// Resolve the public hostname to the test server, which has a self-signed certificate
+		addr := server.Listener.Addr().String()
+		transport := &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, addr)
+			},
+		}
+		client := NewClient("https://pb.example.com", WithSkipTLSForLocalhost(),
+			WithHTTPClient(&http.Client{Transport: transport}))
+
+		_, err := client.GetRecord(context.Background(), "posts", "abc123")
+		var certErr *tls.CertificateVerificationError
+		if !errors.As(err, &certErr) {
+			t.Errorf("Expected a certificate verification error, got %v", err)
+		}
+	})
+
+	t.Run("public host over plain http is refused", func(t *testing.T) {
+		requests := 0
+		client := NewClient("http://pb.example.com", WithSkipTLSForLocalhost(),
+			WithHTTPClient(&http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				requests++
+				return nil, errors.New("unexpected request")
+			})}))
+		client.SetToken("test-token")
+
+		_, err := client.GetRecord(context.Background(), "posts", "abc123")
+		if !errors.Is(err, ErrInsecureAuth) {
+			t.Errorf("Expected ErrInsecureAuth, got %v", err)
+		}
+		if requests != 0 {
+			t.Errorf("Expected no request to be sent, got %d", requests)
+		}
+	})
+
+	t.Run("shared transport is not modified", func(t *testing.T) {
+		transport := &http.Transport{}
+		client := NewClient("https://localhost:8090", WithSkipTLSForLocalhost(),
+			WithHTTPClient(&http.Client{Transport: transport}))
+
+		if transport.TLSClientConfig != nil && transport.TLSClientConfig.InsecureSkipVerify {
+			t.Error("Expected the shared transport to keep verifying certificates")
+		}
+		if err := client.Close(); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+}
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+
+	return f(r)
+}
+
+func TestClient_RegisterType(t *testing.T) {
+	type post struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/collections/posts/records" {
+			json.NewEncoder(w).Encode(listResp{Page: 1, TotalPages: 1, Items: []Record{
+				{"id": "1", "title": "First"},
+				{"id": "2", "title": "Second"},
+			}})
+			return
+		}
+		json.NewEncoder(w).Encode(Record{"id": "abc123", "title": "Hello"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	ctx := context.Background()
+
+	if _, err := client.GetRecordTyped(ctx, "posts", "abc123"); err == nil {
+		t.Error("Expected an error for an unregistered collection")
+	}
+
+	client.RegisterType("posts", &post{})
+
+	record, err := client.GetRecordTyped(ctx, "posts", "abc123")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	p, ok := record.(*post)
+	if !ok {
+		t.Fatalf("Expected *post, got %T", record)
+	}
+	if p.ID != "abc123" || p.Title != "Hello" {
+		t.Errorf("Unexpected decoded record %+v", p)
+	}
+
+	items, err := client.GetAllRecordsTyped(ctx, "posts")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(items) != 2 || items[1].(*post).Title != "Second" {
+		t.Errorf("Unexpected decoded records %v", items)
+	}
+}
+
+func TestWithBodyLogging(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(authResp{Token: "secret-token", Record: Record{"id": "user1", "email": "alice@example.com"}})
+	}))
+	defer server.Close()
+
+	var logs strings.Builder
+	client := NewClient(server.URL, WithBodyLogging(&logs))
+
+	record, err := client.AuthenticateWithPassword(context.Background(), "users", "alice@example.com", "hunter2")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if record["id"] != "user1" || client.GetToken() != "secret-token" {
+		t.Errorf("Expected the logged response to still be decoded, got %v", record)
+	}
+
+	output := logs.String()
+	for _, expected := range []string{
+		"--> POST " + server.URL + "/api/collections/users/auth-with-password",
+		`"identity":"alice@example.com"`,
+		`"password":"[REDACTED]"`,
+		"<-- 200 POST",
+		`"token":"[REDACTED]"`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected body log to contain %s, got:\n%s", expected, output)
+		}
+	}
+	for _, secret := range []string{"hunter2", "secret-token"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("Expected %s to be redacted, got:\n%s", secret, output)
+		}
+	}
+}
+
+func TestClient_IncrementField(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" || r.URL.Path != "/api/collections/posts/records/abc123" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		body = nil
+		json.NewDecoder(r.Body).Decode(&body)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Record{"id": "abc123", "views": 11})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	tests := []struct {
+		name     string
+		delta    float64
+		expected map[string]any
+	}{
+		{"increment", 1, map[string]any{"views+": 1.0}},
+		{"fractional increment", 0.5, map[string]any{"views+": 0.5}},
+		{"decrement", -2, map[string]any{"views-": 2.0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record, err := client.IncrementField(context.Background(), "posts", "abc123", "views", tt.delta)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !maps.Equal(body, tt.expected) {
+				t.Errorf("Expected body %v, got %v", tt.expected, body)
+			}
+			if record["views"] != 11.0 {
+				t.Errorf("Expected updated record, got %v", record)
+			}
+		})
+	}
+}
+
+func TestClient_IsAuthRecordVerified(t *testing.T) {
+	verified := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(authResp{Token: "test-token", Record: Record{"id": "user1", "verified": verified}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	ctx := context.Background()
+
+	if client.IsAuthRecordVerified() {
+		t.Error("Expected false without an auth record")
+	}
+
+	record, err := client.AuthenticateWithPassword(ctx, "users", "alice@example.com", "password")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	record["verified"] = false
+	if !client.IsAuthRecordVerified() {
+		t.Error("Expected verified auth record")
+	}
+	if client.AuthRecord()["id"] != "user1" {
+		t.Errorf("Expected stored auth record, got %v", client.AuthRecord())
+	}
+
+	// Modifying the returned records doesn't affect the stored one
+	client.AuthRecord()["verified"] = false
+	if !client.IsAuthRecordVerified() {
+		t.Error("Expected the stored auth record to be unaffected by changes to a copy")
+	}
+
+	verified = false
+	if _, err := client.AuthenticateWithPassword(ctx, "users", "bob@example.com", "password"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if client.IsAuthRecordVerified() {
+		t.Error("Expected unverified auth record")
+	}
+
+	client.SetToken("manual-token")
+	if client.AuthRecord() != nil {
+		t.Errorf("Expected SetToken to clear the auth record, got %v", client.AuthRecord())
+	}
+}
+
+func TestWithMaxPages(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+		// Always claim there is another page
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(listResp{Page: page, PerPage: 1, TotalPages: page + 1, Items: []Record{{"id": strconv.Itoa(page)}}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	records, err := client.GetAllRecords(context.Background(), "posts", WithMaxPages(5))
+	if !errors.Is(err, ErrMaxPagesExceeded) {
+		t.Errorf("Expected ErrMaxPagesExceeded, got %v", err)
+	}
+	if records != nil {
+		t.Errorf("Expected no records, got %d", len(records))
+	}
+	if requests != 5 {
+		t.Errorf("Expected 5 page requests, got %d", requests)
+	}
+}
+
+func TestWithMaxPages_SkipTotal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+		// Full pages without totals, as returned with skipTotal
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(listResp{Page: page, PerPage: 1, TotalItems: -1, TotalPages: -1, Items: []Record{{"id": strconv.Itoa(page)}}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	_, err := client.GetAllRecords(context.Background(), "posts", WithPerPage(1), WithSkipTotal(), WithMaxPages(3))
+	if !errors.Is(err, ErrMaxPagesExceeded) {
+		t.Fatalf("Expected ErrMaxPagesExceeded, got %v", err)
+	}
+	if expected := "pocketbase: maximum number of pages exceeded: stopped after 3 pages"; err.Error() != expected {
+		t.Errorf("Expected error '%s', got '%s'", expected, err.Error())
+	}
+}
+
+func TestClient_Close(t *testing.T) {
+	var refreshes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshes.Add(1)
+		time.Sleep(10 * time.Millisecond)
+
+		// Every token is due for refresh again right away
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(authResp{Token: testToken(map[string]any{"exp": time.Now().Add(time.Second).Unix()})})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.SetToken(testToken(map[string]any{"exp": time.Now().Add(time.Second).Unix()}))
+	client.StartAutoRefresh(context.Background(), "users", time.Minute)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for refreshes.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if refreshes.Load() < 2 {
+		t.Fatal("Expected the auto refresh goroutine to be running")
+	}
+
+	// Close concurrently to check it is idempotent
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := client.Close(); err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Let a refresh in flight during Close finish
+	time.Sleep(50 * time.Millisecond)
+	stopped := refreshes.Load()
+	time.Sleep(100 * time.Millisecond)
+	if refreshes.Load() != stopped {
+		t.Errorf("Expected auto refresh to stop after Close, got %d more refreshes", refreshes.Load()-stopped)
+	}
+}
+
+func TestClient_CancelAll(t *testing.T) {
+	started := make(chan struct{}, 3)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/slow/") {
+			started <- struct{}{}
+			select {
+			case <-release:
+			case <-r.Context().Done():
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Record{"id": "abc"})
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(server.URL)
+
+	errs := make(chan error, 3)
+	for range 3 {
+		go func() {
+			_, err := client.GetRecord(context.Background(), "slow", "abc")
+			errs <- err
+		}()
+	}
+	for range 3 {
+		<-started
+	}
+
+	client.CancelAll()
+
+	for range 3 {
+		select {
+		case err := <-errs:
+			if !errors.Is(err, ErrRequestsCanceled) {
+				t.Errorf("Expected ErrRequestsCanceled, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected in-flight requests to be canceled")
+		}
+	}
+
+	if _, err := client.GetRecord(context.Background(), "posts", "abc"); !errors.Is(err, ErrRequestsCanceled) {
+		t.Errorf("Expected new requests to fail with ErrRequestsCanceled, got %v", err)
+	}
+	if _, err := client.DownloadFile(context.Background(), "posts", "abc", "a.txt"); !errors.Is(err, ErrRequestsCanceled) {
+		t.Errorf("Expected downloads to fail with ErrRequestsCanceled, got %v", err)
+	}
+
+	client.ResumeRequests()
+
+	record, err := client.GetRecord(context.Background(), "posts", "abc")
+	if err != nil {
+		t.Fatalf("Expected no error after ResumeRequests, got %v", err)
+	}
+	if record["id"] != "abc" {
+		t.Errorf("Expected record abc, got %v", record["id"])
+	}
+
+	// A canceled per-call context is not reported as CancelAll
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.GetRecord(ctx, "posts", "abc"); err == nil || errors.Is(err, ErrRequestsCanceled) {
+		t.Errorf("Expected a context error not wrapping ErrRequestsCanceled, got %v", err)
+	}
+}
+
+func TestWithAllowDestructiveOnRemote(t *testing.T) {
+	var requests []string
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		requests = append(requests, r.Method+" "+r.URL.String())
+		return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody, Request: r}, nil
+	})
+
+	t.Run("remote is blocked by default", func(t *testing.T) {
+		requests = nil
+		client := NewClient("https://pb.example.com", WithHTTPClient(&http.Client{Transport: transport}))
+
+		err := client.TruncateCollectionFast(context.Background(), "posts")
+		if !errors.Is(err, ErrDestructiveOnRemote) {
+			t.Errorf("Expected ErrDestructiveOnRemote, got %v", err)
+		}
+		if len(requests) != 0 {
+			t.Errorf("Expected no request to be sent, got %v", requests)
+		}
+	})
+
+	t.Run("remote is allowed explicitly", func(t *testing.T) {
+		requests = nil
+		client := NewClient("https://pb.example.com", WithHTTPClient(&http.Client{Transport: transport}),
+			WithAllowDestructiveOnRemote())
+
+		if err := client.TruncateCollectionFast(context.Background(), "posts"); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if len(requests) != 1 || requests[0] != "DELETE https://pb.example.com/api/collections/posts/truncate" {
+			t.Errorf("Expected the truncate request, got %v", requests)
+		}
+	})
+}
+
+func TestWithResponseHeaderTimeout(t *testing.T) {
+	client := NewClient("http://localhost:8090", WithResponseHeaderTimeout(10*time.Second), WithIdleTimeout(time.Minute))
+
+	transport, ok := client.HTTPClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("Expected *http.Transport, got %T", client.HTTPClient.Transport)
+	}
+	if transport.ResponseHeaderTimeout != 10*time.Second {
+		t.Errorf("Expected ResponseHeaderTimeout 10s, got %v", transport.ResponseHeaderTimeout)
+	}
+	if transport.IdleConnTimeout != time.Minute {
+		t.Errorf("Expected IdleConnTimeout 1m, got %v", transport.IdleConnTimeout)
+	}
+	if client.HTTPClient.Timeout != 0 {
+		t.Errorf("Expected request timeout to be unaffected, got %v", client.HTTPClient.Timeout)
+	}
+}
+
+func TestWithBatchQuery(t *testing.T) {
+	var urls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Requests []struct {
+				URL string `json:"url"`
+			} `json:"requests"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("Failed to decode batch body: %v", err)
+		}
+		urls = nil
+		for _, request := range payload.Requests {
+			urls = append(urls, request.URL)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]BatchResult{{Status: 200}, {Status: 200}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	_, err := client.NewBatch().
+		Create("posts", Record{"title": "Hello"}, WithBatchQuery(WithExpand("author", "tags"), WithFields("id", "expand"))).
+		Update("posts", "abc123", Record{"title": "Updated"}).
+		Send(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{
+		"/api/collections/posts/records?expand=author%2Ctags&fields=id%2Cexpand",
+		"/api/collections/posts/records/abc123",
+	}
+	if !slices.Equal(urls, expected) {
+		t.Errorf("Expected request URLs %v, got %v", expected, urls)
+	}
+}
+
+func TestWithRetry_Authentication(t *testing.T) {
+	t.Run("retries a 503", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.Header().Set("Content-Type", "application/json")
+			if attempts == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				json.NewEncoder(w).Encode(apiErrorResp{Status: 503, Message: "Service unavailable."})
+				return
+			}
+			json.NewEncoder(w).Encode(authResp{Token: "test-token", Record: Record{"id": "admin1"}})
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL, WithRetry(2), WithBackoff(func(int) time.Duration { return 0 }))
+
+		if _, err := client.AuthenticateAsSuperuser(context.Background(), "admin@example.com", "password"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if attempts != 2 {
+			t.Errorf("Expected 2 attempts, got %d", attempts)
+		}
+		if client.GetToken() != "test-token" {
+			t.Errorf("Expected token to be stored, got '%s'", client.GetToken())
+		}
+	})
+
+	t.Run("doesn't retry a 400", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(apiErrorResp{Status: 400, Message: "Failed to authenticate."})
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL, WithRetry(2), WithBackoff(func(int) time.Duration { return 0 }))
+
+		_, err := client.AuthenticateWithPassword(context.Background(), "users", "alice@example.com", "wrong")
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || !apiErr.IsBadRequest() {
+			t.Errorf("Expected 400 APIError, got %v", err)
+		}
+		if attempts != 1 {
+			t.Errorf("Expected a single attempt, got %d", attempts)
+		}
+	})
+
+	t.Run("other POSTs are not retried", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL, WithRetry(2), WithBackoff(func(int) time.Duration { return 0 }))
+
+		if _, err := client.CreateRecord(context.Background(), "posts", Record{"title": "Hello"}); err == nil {
+			t.Error("Expected an error")
+		}
+		if attempts != 1 {
+			t.Errorf("Expected a single attempt, got %d", attempts)
+		}
+	})
+}
+
+func TestWithExpectedUpdated(t *testing.T) {
+	current := "2024-01-02 03:04:05.000Z"
+	patched := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case "GET":
+			if r.URL.Query().Get("fields") != "updated" {
+				t.Errorf("Expected only the updated field to be fetched, got '%s'", r.URL.Query().Get("fields"))
+			}
+			json.NewEncoder(w).Encode(Record{"updated": current})
+		case "PATCH":
+			patched = true
+			json.NewEncoder(w).Encode(Record{"id": "abc123", "title": "New title"})
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	t.Run("matching timestamp updates", func(t *testing.T) {
+		patched = false
+		_, err := client.UpdateRecord(context.Background(), "posts", "abc123", Record{"title": "New title"},
+			WithExpectedUpdated("2024-01-02 03:04:05.000Z"))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !patched {
+			t.Error("Expected the record to be updated")
+		}
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		patched = false
+		_, err := client.UpdateRecord(context.Background(), "posts", "abc123", Record{"title": "New title"},
+			WithExpectedUpdated("2024-01-01 00:00:00.000Z"))
+		if !errors.Is(err, ErrUpdateConflict) {
+			t.Errorf("Expected ErrUpdateConflict, got %v", err)
+		}
+		if patched {
+			t.Error("Expected the record not to be updated on conflict")
+		}
+	})
+}
+
+func TestClient_GetCollectionFields(t *testing.T) {
+	t.Run("inline options", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/collections/products" {
+				t.Errorf("Expected path '/api/collections/products', got '%s'", r.URL.Path)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"name": "products", "fields": [
+				{"id": "f1", "name": "title", "type": "text", "required": true, "system": false, "hidden": false, "presentable": true, "min": 3, "max": 100, "pattern": ""},
+				{"id": "f2", "name": "price", "type": "number", "required": false, "min": 0, "onlyInt": false},
+				{"id": "f3", "name": "category", "type": "relation", "required": true, "collectionId": "pbc_123", "maxSelect": 1, "cascadeDelete": false}
+			]}`))
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+		fields, err := client.GetCollectionFields(context.Background(), "products")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(fields) != 3 {
+			t.Fatalf("Expected 3 fields, got %d", len(fields))
+		}
+
+		title := fields[0]
+		if title.Name != "title" || title.Type != "text" || !title.Required {
+			t.Errorf("Expected required text field 'title', got %+v", title)
+		}
+		if title.Options["min"] != float64(3) || title.Options["max"] != float64(100) {
+			t.Errorf("Expected text options min and max, got %v", title.Options)
+		}
+		for _, key := range []string{"id", "name", "type", "required", "system", "hidden", "presentable"} {
+			if _, ok := title.Options[key]; ok {
+				t.Errorf("Expected '%s' not to be an option", key)
+			}
+		}
+
+		price := fields[1]
+		if price.Name != "price" || price.Type != "number" || price.Required {
+			t.Errorf("Expected optional number field 'price', got %+v", price)
+		}
+		if price.Options["onlyInt"] != false {
+			t.Errorf("Expected onlyInt option, got %v", price.Options)
+		}
+
+		category := fields[2]
+		if category.Type != "relation" || category.Options["collectionId"] != "pbc_123" || category.Options["maxSelect"] != float64(1) {
+			t.Errorf("Expected relation options, got %+v", category)
+		}
+	})
+
+	t.Run("legacy schema with nested options", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"name": "products", "schema": [
+				{"id": "f1", "name": "title", "type": "text", "required": true, "options": {"min": 3}}
+			]}`))
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+		fields, err := client.GetCollectionFields(context.Background(), "products")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(fields) != 1 || fields[0].Name != "title" || !fields[0].Required || fields[0].Options["min"] != float64(3) {
+			t.Errorf("Expected legacy field definition, got %+v", fields)
+		}
+	})
+}
+
+func TestClient_GetRecordWithFileURLs(t *testing.T) {
+	tokenRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/collections/documents":
+			json.NewEncoder(w).Encode(map[string]any{"fields": []map[string]any{
+				{"name": "title", "type": "text"},
+				{"name": "cover", "type": "file"},
+				{"name": "attachments", "type": "file", "protected": true},
+				{"name": "thumbnail", "type": "file"},
+			}})
+		case "/api/files/token":
+			tokenRequests++
+			json.NewEncoder(w).Encode(map[string]any{"token": "file-token"})
+		case "/api/collections/documents/records/doc1":
+			json.NewEncoder(w).Encode(Record{
+				"id":          "doc1",
+				"title":       "report.pdf",
+				"cover":       "cover_abc.png",
+				"attachments": []any{"a_123.pdf", "b_456.pdf"},
+				"thumbnail":   "",
+			})
+		default:
+			t.Errorf("Unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	record, err := client.GetRecordWithFileURLs(context.Background(), "documents", "doc1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	base := server.URL + "/api/files/documents/doc1/"
+	if record["cover"] != base+"cover_abc.png" {
+		t.Errorf("Expected cover URL, got %v", record["cover"])
+	}
+	attachments, _ := record["attachments"].([]any)
+	if len(attachments) != 2 || attachments[0] != base+"a_123.pdf?token=file-token" || attachments[1] != base+"b_456.pdf?token=file-token" {
+		t.Errorf("Expected protected attachment URLs, got %v", record["attachments"])
+	}
+	if record["title"] != "report.pdf" || record["thumbnail"] != "" {
+		t.Errorf("Expected other fields to be unchanged, got %v", record)
+	}
+	if tokenRequests != 1 {
+		t.Errorf("Expected a single file token request, got %d", tokenRequests)
+	}
+}
+
+func TestClient_GetRecordWithFileURLs_ExplicitFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/collections/documents":
+			// Regular users can't read the collection schema
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(apiErrorResp{Status: 403, Message: "Only superusers can perform this action."})
+		case "/api/files/token":
+			json.NewEncoder(w).Encode(map[string]any{"token": "file-token"})
+		case "/api/collections/documents/records/doc1":
+			if fields := r.URL.Query().Get("fields"); fields != "id,title,cover,attachments" {
+				t.Errorf("Expected fields 'id,title,cover,attachments', got '%s'", fields)
+			}
+			json.NewEncoder(w).Encode(Record{
+				"id":          "doc1",
+				"title":       "report.pdf",
+				"cover":       "cover_abc.png",
+				"attachments": []any{"a_123.pdf"},
+			})
+		default:
+			t.Errorf("Unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.SetToken("user-token")
+
+	record, err := client.GetRecordWithFileURLs(context.Background(), "documents", "doc1",
+		WithFileFields("cover"), WithProtectedFileFields("attachments"),
+		WithFileURLQuery(WithFields("id", "title", "cover", "attachments")))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	base := server.URL + "/api/files/documents/doc1/"
+	if record["cover"] != base+"cover_abc.png" {
+		t.Errorf("Expected cover URL, got %v", record["cover"])
+	}
+	attachments, _ := record["attachments"].([]any)
+	if len(attachments) != 1 || attachments[0] != base+"a_123.pdf?token=file-token" {
+		t.Errorf("Expected protected attachment URL, got %v", record["attachments"])
+	}
+	if record["title"] != "report.pdf" {
+		t.Errorf("Expected other fields to be unchanged, got %v", record["title"])
+	}
+
+	// Without explicit fields the schema is read, which is forbidden here
+	var apiErr *APIError
+	if _, err := client.GetRecordWithFileURLs(context.Background(), "documents", "doc1"); !errors.As(err, &apiErr) || apiErr.Status != 403 {
+		t.Errorf("Expected a 403 error from the schema fallback, got %v", err)
+	}
+}
+
+func TestClient_GetRecordWithFileURLs_SchemaFormats(t *testing.T) {
+	tests := []struct {
+		name       string
+		collection map[string]any
+	}{
+		{
+			name: "inline fields (v0.23+)",
+			collection: map[string]any{"fields": []map[string]any{
+				{"name": "cover", "type": "file", "protected": false},
+				{"name": "attachments", "type": "file", "protected": true},
+			}},
+		},
+		{
+			name: "nested schema options (older versions)",
+			collection: map[string]any{"schema": []map[string]any{
+				{"name": "cover", "type": "file", "options": map[string]any{"protected": false}},
+				{"name": "attachments", "type": "file", "options": map[string]any{"protected": true}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/api/collections/documents":
+					json.NewEncoder(w).Encode(tt.collection)
+				case "/api/files/token":
+					json.NewEncoder(w).Encode(map[string]any{"token": "file-token"})
+				case "/api/collections/documents/records/doc1":
+					json.NewEncoder(w).Encode(Record{
+						"id":          "doc1",
+						"cover":       "cover_abc.png",
+						"attachments": []any{"a_123.pdf"},
+					})
+				default:
+					t.Errorf("Unexpected request %s", r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL)
+			record, err := client.GetRecordWithFileURLs(context.Background(), "documents", "doc1")
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			base := server.URL + "/api/files/documents/doc1/"
+			if record["cover"] != base+"cover_abc.png" {
+				t.Errorf("Expected cover URL without a token, got %v", record["cover"])
+			}
+			attachments, _ := record["attachments"].([]any)
+			if len(attachments) != 1 || attachments[0] != base+"a_123.pdf?token=file-token" {
+				t.Errorf("Expected protected attachment URL with a token, got %v", record["attachments"])
+			}
+		})
+	}
+}
+
+func TestClient_ExportCollection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("filter") != "status='published'" {
+			t.Errorf("Expected filter to be forwarded, got '%s'", r.URL.Query().Get("filter"))
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		items := []Record{{"id": "a", "title": "First"}, {"id": "b", "title": "Second"}}
+		if page == 2 {
+			items = []Record{{"id": "c", "title": "Third"}}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(listResp{Page: page, PerPage: 2, TotalPages: 2, Items: items})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	var out strings.Builder
+	n, err := client.ExportCollection(context.Background(), "posts", &out, WithFilter("status='published'"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if n != 3 {
+		t.Errorf("Expected 3 exported records, got %d", n)
+	}
+
+	expected := `{"id":"a","title":"First"}` + "\n" +
+		`{"id":"b","title":"Second"}` + "\n" +
+		`{"id":"c","title":"Third"}` + "\n"
+	if out.String() != expected {
+		t.Errorf("Expected NDJSON output %q, got %q", expected, out.String())
+	}
+}
+
+func TestClient_ImportCollection(t *testing.T) {
+	var created []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body Record
+		json.NewDecoder(r.Body).Decode(&body)
+
+		switch {
+		case r.Method == "PATCH" && r.URL.Path == "/api/collections/posts/records/existing":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id":"existing"}`))
+		case r.Method == "PATCH":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"status":404,"message":"The requested resource wasn't found."}`))
+		case r.Method == "POST" && body["title"] == "":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":400,"message":"Failed to create record.","data":{"title":{"code":"validation_required","message":"Missing required value."}}}`))
+		case r.Method == "POST":
+			created = append(created, body["title"].(string))
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id":"new"}`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	input := `{"id":"existing","title":"Updated"}` + "\n" +
+		`{"id":"missing","title":"Restored"}` + "\n" +
+		"\n" +
+		`{"title":""}` + "\n" +
+		`{"title":"Fresh"}`
+
+	t.Run("continues after failures", func(t *testing.T) {
+		created = nil
+		imported, failed, err := client.ImportCollection(context.Background(), "posts", strings.NewReader(input), WithImportUpsert())
+		if imported != 3 || failed != 1 {
+			t.Errorf("Expected 3 imported and 1 failed, got %d and %d", imported, failed)
+		}
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || !apiErr.IsBadRequest() {
+			t.Errorf("Expected the validation error, got %v", err)
+		}
+		if err == nil || !strings.Contains(err.Error(), "line 4") {
+			t.Errorf("Expected error to mention the failed line, got %v", err)
+		}
+		if !slices.Equal(created, []string{"Restored", "Fresh"}) {
+			t.Errorf("Expected missing and new records to be created, got %v", created)
+		}
+	})
+
+	t.Run("stops on error", func(t *testing.T) {
+		created = nil
+		imported, failed, err := client.ImportCollection(context.Background(), "posts", strings.NewReader(input),
+			WithImportUpsert(), WithImportStopOnError())
+		if imported != 2 || failed != 1 || err == nil {
+			t.Errorf("Expected 2 imported, 1 failed and an error, got %d, %d and %v", imported, failed, err)
+		}
+		if !slices.Equal(created, []string{"Restored"}) {
+			t.Errorf("Expected import to stop before the last record, got %v", created)
+		}
+	})
+}
+
+func TestClient_Superuser(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/logs":
+			json.NewEncoder(w).Encode(listResp{Page: 1, PerPage: 30, TotalPages: 1, Items: []Record{{"id": "log1"}}})
+		case "/api/collections/users/impersonate/u1":
+			w.Write([]byte(`{"token":"impersonated","record":{"id":"u1"}}`))
+		case "/api/collections/posts/truncate":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.setAuth("superuser-token", Record{"id": "su1", "collectionName": "_superusers"})
+	superuser := client.Superuser()
+	ctx := context.Background()
+
+	if _, err := superuser.GetLogs(ctx); err != nil {
+		t.Errorf("Expected no error from GetLogs, got %v", err)
+	}
+	if _, err := superuser.GetAllLogs(ctx); err != nil {
+		t.Errorf("Expected no error from GetAllLogs, got %v", err)
+	}
+	if result, err := superuser.Impersonate(ctx, "users", "u1", 60); err != nil || result.Token != "impersonated" {
+		t.Errorf("Expected impersonation token, got %v, %v", result, err)
+	}
+	if err := superuser.TruncateCollection(ctx, "posts"); err != nil {
+		t.Errorf("Expected no error from TruncateCollection, got %v", err)
+	}
+
+	expected := []string{
+		"GET /api/logs",
+		"GET /api/logs",
+		"POST /api/collections/users/impersonate/u1",
+		"DELETE /api/collections/posts/truncate",
+	}
+	if !slices.Equal(requests, expected) {
+		t.Errorf("Expected requests %v, got %v", expected, requests)
+	}
+
+	t.Run("requires superuser", func(t *testing.T) {
+		requests = nil
+
+		regular := NewClient(server.URL)
+		regular.setAuth("user-token", Record{"id": "u1", "collectionName": "users"})
+		if _, err := regular.Superuser().GetLogs(ctx); !errors.Is(err, ErrSuperuserRequired) {
+			t.Errorf("Expected ErrSuperuserRequired for a regular user, got %v", err)
+		}
+
+		anonymous := NewClient(server.URL)
+		if err := anonymous.Superuser().TruncateCollection(ctx, "posts"); !errors.Is(err, ErrSuperuserRequired) {
+			t.Errorf("Expected ErrSuperuserRequired without a token, got %v", err)
+		}
+
+		if len(requests) != 0 {
+			t.Errorf("Expected no requests to be sent, got %v", requests)
+		}
+	})
+}
+
+func TestWithSkipTotal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		page, _ := strconv.Atoi(query.Get("page"))
+		perPage, _ := strconv.Atoi(query.Get("perPage"))
+
+		resp := listResp{Page: page, PerPage: perPage, TotalItems: 3, TotalPages: 2}
+		if query.Get("skipTotal") == "1" {
+			resp.TotalItems, resp.TotalPages = -1, -1
+		}
+		for i := (page - 1) * perPage; i < min(page*perPage, 3); i++ {
+			resp.Items = append(resp.Items, Record{"id": strconv.Itoa(i)})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithDefaultListOptions(WithSkipTotal(), WithPerPage(2)))
+
+	t.Run("CountRecords still counts", func(t *testing.T) {
+		count, err := client.CountRecords(context.Background(), "posts")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if count != 3 {
+			t.Errorf("Expected count 3, got %d", count)
+		}
+	})
+
+	t.Run("pagination stops at the first partial page", func(t *testing.T) {
+		records, err := client.GetAllRecords(context.Background(), "posts")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(records) != 3 {
+			t.Errorf("Expected 3 records, got %d", len(records))
+		}
+	})
+}
+
+func TestClient_CreateRecordsStream(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	var rateLimited atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			peak := maxInFlight.Load()
+			if current <= peak || maxInFlight.CompareAndSwap(peak, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		// Rate limit the first request once
+		if rateLimited.CompareAndSwap(false, true) {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		var body Record
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["n"] == float64(7) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":400,"message":"Failed to create record."}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Record{"id": fmt.Sprintf("id-%v", body["n"]), "n": body["n"]})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	const total = 20
+	records := make(chan Record)
+	go func() {
+		defer close(records)
+		for i := range total {
+			records <- Record{"n": i}
+		}
+	}()
+
+	results, err := client.CreateRecordsStream(context.Background(), "items", records, 3)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	created, failed := 0, 0
+	for result := range results {
+		if result.Err != nil {
+			failed++
+			if result.Input["n"] != 7 {
+				t.Errorf("Expected only record 7 to fail, got %v: %v", result.Input["n"], result.Err)
+			}
+			continue
+		}
+		created++
+		if result.Record["id"] != fmt.Sprintf("id-%v", result.Input["n"]) {
+			t.Errorf("Expected result to match its input, got %v for %v", result.Record["id"], result.Input["n"])
+		}
+	}
+
+	if created != total-1 || failed != 1 {
+		t.Errorf("Expected %d created and 1 failed, got %d and %d", total-1, created, failed)
+	}
+	if peak := maxInFlight.Load(); peak > 3 {
+		t.Errorf("Expected at most 3 concurrent requests, got %d", peak)
+	}
+
+	if _, err := client.CreateRecordsStream(context.Background(), "items", records, 0); err == nil {
+		t.Error("Expected an error for a concurrency of zero")
+	}
+}
+
+func TestClient_ProcessAllRecords(t *testing.T) {
+	const perPage, totalPages = 10, 5
+
+	var pages atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages.Add(1)
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+		items := make([]Record, perPage)
+		for i := range items {
+			items[i] = Record{"id": fmt.Sprintf("rec%d", (page-1)*perPage+i)}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"page": page, "perPage": perPage, "totalItems": perPage * totalPages, "totalPages": totalPages, "items": items,
+		})
+	}))
+	defer server.Close()
+
+	t.Run("processes all records", func(t *testing.T) {
+		pages.Store(0)
+		client := NewClient(server.URL)
+
+		var mu sync.Mutex
+		processed := map[string]bool{}
+		err := client.ProcessAllRecords(context.Background(), "orders", 4, func(record Record) error {
+			mu.Lock()
+			defer mu.Unlock()
+			processed[record["id"].(string)] = true
+			return nil
+		}, WithPerPage(perPage))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(processed) != perPage*totalPages {
+			t.Errorf("Expected %d processed records, got %d", perPage*totalPages, len(processed))
+		}
+		if pages.Load() != totalPages {
+			t.Errorf("Expected %d page requests, got %d", totalPages, pages.Load())
+		}
+	})
+
+	t.Run("error aborts processing", func(t *testing.T) {
+		pages.Store(0)
+		client := NewClient(server.URL)
+
+		errBroken := errors.New("broken record")
+		var processed atomic.Int32
+		err := client.ProcessAllRecords(context.Background(), "orders", 2, func(record Record) error {
+			if record["id"] == "rec3" {
+				return errBroken
+			}
+			time.Sleep(10 * time.Millisecond)
+			processed.Add(1)
+			return nil
+		}, WithPerPage(perPage))
+		if !errors.Is(err, errBroken) {
+			t.Fatalf("Expected the processing error, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "rec3") {
+			t.Errorf("Expected the error to mention the record ID, got %v", err)
+		}
+		if processed.Load() >= perPage*totalPages-1 {
+			t.Errorf("Expected the remaining records to be skipped, got %d processed", processed.Load())
+		}
+		if pages.Load() == totalPages {
+			t.Errorf("Expected fetching to stop early, got %d page requests", pages.Load())
+		}
+	})
+
+	t.Run("invalid worker count", func(t *testing.T) {
+		client := NewClient(server.URL)
+		if err := client.ProcessAllRecords(context.Background(), "orders", 0, func(Record) error { return nil }); err == nil {
+			t.Error("Expected an error for zero workers")
+		}
+	})
+}
+
+func TestWithRateLimitObserver(t *testing.T) {
+	reset := time.Now().Add(time.Minute).Truncate(time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/collections/posts/records/limited" {
+			w.Header().Set("X-RateLimit-Limit", "100")
+			w.Header().Set("X-RateLimit-Remaining", "7")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"abc"}`))
+	}))
+	defer server.Close()
+
+	var observed []RateLimitState
+	client := NewClient(server.URL, WithRateLimitObserver(func(state RateLimitState) {
+		observed = append(observed, state)
+	}))
+
+	if _, err := client.GetRecord(context.Background(), "posts", "plain"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, ok := client.RateLimitState(); ok || len(observed) != 0 {
+		t.Errorf("Expected responses without headers to be ignored, got %v", observed)
+	}
+
+	if _, err := client.GetRecord(context.Background(), "posts", "limited"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	state, ok := client.RateLimitState()
+	if !ok {
+		t.Fatal("Expected a rate limit state")
+	}
+	if state.Limit != 100 || state.Remaining != 7 || !state.Reset.Equal(reset) {
+		t.Errorf("Expected limit 100, remaining 7 and reset %v, got %+v", reset, state)
+	}
+	if len(observed) != 1 || observed[0] != state {
+		t.Errorf("Expected the observer to receive the state once, got %v", observed)
+	}
+
+	t.Run("reset in seconds", func(t *testing.T) {
+		now := time.Now()
+		header := http.Header{}
+		header.Set("X-RateLimit-Remaining", "0")
+		header.Set("X-RateLimit-Reset", "30")
+
+		state, ok := parseRateLimit(header, now)
+		if !ok || state.Limit != -1 || !state.Reset.Equal(now.Add(30*time.Second)) {
+			t.Errorf("Expected reset in 30s without a limit, got %+v", state)
+		}
+	})
+}
+
+func TestClient_SetBaseURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"abc"}`))
+	}))
+	defer server.Close()
+
+	t.Run("valid URL", func(t *testing.T) {
+		client := NewClient("http://unreachable.invalid")
+		if err := client.SetBaseURL(server.URL); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, err := client.GetRecord(context.Background(), "posts", "abc"); err != nil {
+			t.Errorf("Expected requests to use the new base URL, got %v", err)
+		}
+	})
+
+	t.Run("trailing slash", func(t *testing.T) {
+		client := NewClient("http://localhost:8090")
+		if err := client.SetBaseURL("https://pb.example.com/"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if client.BaseURL != "https://pb.example.com" {
+			t.Errorf("Expected trailing slash to be trimmed, got '%s'", client.BaseURL)
+		}
+	})
+
+	t.Run("invalid URLs", func(t *testing.T) {
+		client := NewClient("http://localhost:8090")
+		for _, baseURL := range []string{"pb.example.com", "ftp://pb.example.com", "http://", "://bad"} {
+			if err := client.SetBaseURL(baseURL); err == nil {
+				t.Errorf("Expected an error for '%s'", baseURL)
+			}
+		}
+		if client.BaseURL != "http://localhost:8090" {
+			t.Errorf("Expected base URL to be unchanged, got '%s'", client.BaseURL)
+		}
+	})
+}
+
+func TestClient_WaitForRecord(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		status := "running"
+		if polls >= 3 {
+			status = "done"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Record{"id": "job-1", "status": status})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	done := func(job Record) bool { return job["status"] == "done" }
+
+	job, err := client.WaitForRecord(context.Background(), "jobs", "job-1", done, time.Millisecond)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if job["status"] != "done" {
+		t.Errorf("Expected the final record, got %v", job)
+	}
+	if polls != 3 {
+		t.Errorf("Expected 3 polls, got %d", polls)
+	}
+
+	t.Run("context expires", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		never := func(Record) bool { return false }
+		if _, err := client.WaitForRecord(ctx, "jobs", "job-1", never, 5*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,158 @@
+package pocketbase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+)
+
+// FieldDef describes a field of a collection schema, e.g. for rendering a form from it.
+type FieldDef struct {
+	Name     string
+	Type     string // e.g. "text", "number", "bool", "relation" or "file"
+	Required bool
+	// Options holds the type-specific settings of the field, e.g. "min", "max" and
+	// "pattern" for text fields or "collectionId" and "maxSelect" for relations.
+	Options map[string]any
+}
+
+// fieldDefAttributes are the field definition keys that aren't type-specific options.
+var fieldDefAttributes = []string{"id", "name", "type", "required", "system", "hidden", "presentable"}
+
+// UnmarshalJSON decodes a field definition. PocketBase v0.23+ inlines the type-specific
+// options in the field object, older versions nest them in an "options" object; both
+// are decoded into Options.
+func (f *FieldDef) UnmarshalJSON(data []byte) error {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	f.Name, _ = raw["name"].(string)
+	f.Type, _ = raw["type"].(string)
+	f.Required, _ = raw["required"].(bool)
+
+	if options, ok := raw["options"].(map[string]any); ok {
+		f.Options = options
+		return nil
+	}
+
+	f.Options = map[string]any{}
+	for key, value := range raw {
+		if !slices.Contains(fieldDefAttributes, key) {
+			f.Options[key] = value
+		}
+	}
+	return nil
+}
+
+// GetCollectionFields fetches the field definitions of a collection schema, with their
+// type, whether they are required and their type-specific options. It powers generic
+// UIs rendering forms from the schema. Reading a collection definition requires superuser
+// authentication.
+//
+// Example:
+//
+//	fields, err := client.GetCollectionFields(ctx, "posts")
+//	if err != nil {
+//		return err
+//	}
+//	for _, field := range fields {
+//		fmt.Printf("%s (%s) required=%v\n", field.Name, field.Type, field.Required)
+//	}
+func (c *Client) GetCollectionFields(ctx context.Context, collection string) ([]FieldDef, error) {
+	return c.collectionFields(ctx, collection)
+}
+
+// collectionFields fetches the fields of a collection schema, named "schema" before
+// PocketBase v0.23.
+func (c *Client) collectionFields(ctx context.Context, collection string) ([]FieldDef, error) {
+	var resp struct {
+		Fields []FieldDef `json:"fields"`
+		Schema []FieldDef `json:"schema"`
+	}
+	endpoint := fmt.Sprintf("/api/collections/%s", url.PathEscape(collection))
+	if err := c.doRequest(ctx, "GET", endpoint, nil, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Fields == nil {
+		return resp.Schema, nil
+	}
+	return resp.Fields, nil
+}
+
+// TruncateCollectionFast deletes all records of a collection using the server-side
+// truncate endpoint, which is far faster than deleting the records one by one.
+// Requires superuser authentication. The truncate endpoint is available in PocketBase
+// v0.22 and newer; when the server responds with 404 Not Found for it (older versions),
+// the records are deleted one by one instead.
+//
+// As a guard against wiping production data by mistake, it fails with an error wrapping
+// ErrDestructiveOnRemote when the client BaseURL isn't localhost, unless the client was
+// created with WithAllowDestructiveOnRemote.
+//
+// Example:
+//
+//	// Reset a test database between test runs
+//	if err := client.TruncateCollectionFast(ctx, "posts"); err != nil {
+//		return err
+//	}
+func (c *Client) TruncateCollectionFast(ctx context.Context, collection string) error {
+	if err := c.checkDestructiveAllowed(); err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("/api/collections/%s/truncate", url.PathEscape(collection))
+
+	err := c.doRequest(ctx, "DELETE", endpoint, nil, nil)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.IsNotFound() {
+		return c.deleteAllRecords(ctx, collection)
+	}
+
+	return err
+}
+
+// deleteAllRecords deletes every record of a collection one by one, fetching the
+// record IDs a page at a time until the collection is empty.
+func (c *Client) deleteAllRecords(ctx context.Context, collection string) error {
+	options := &ListOptions{
+		PerPage: maxPerPage,
+		Fields:  []string{"id"},
+	}
+
+	for {
+		resp, err := c.getRecordPage(ctx, collection, options, 1)
+		if err != nil {
+			return err
+		}
+		if len(resp.Items) == 0 {
+			return nil
+		}
+
+		for _, record := range resp.Items {
+			id, _ := record["id"].(string)
+			if _, err := c.DeleteRecord(ctx, collection, id, WithFields("id")); err != nil {
+				return fmt.Errorf("failed to delete record %s: %w", id, err)
+			}
+		}
+	}
+}
+
+// checkDestructiveAllowed returns an error wrapping ErrDestructiveOnRemote when the
+// client targets a remote server without WithAllowDestructiveOnRemote.
+func (c *Client) checkDestructiveAllowed() error {
+	if c.allowDestructiveOnRemote {
+		return nil
+	}
+
+	u, err := url.Parse(c.baseURL())
+	if err != nil || !isLocalhost(u.Hostname()) {
+		return fmt.Errorf("%w: %s", ErrDestructiveOnRemote, c.baseURL())
+	}
+	return nil
+}
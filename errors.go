@@ -1,6 +1,46 @@
 package pocketbase
 
-import "fmt"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrMultipleRecords is returned when a lookup expected a single record but found several.
+var ErrMultipleRecords = errors.New("pocketbase: multiple records match")
+
+// ErrUpdateConflict is returned by UpdateRecord with WithExpectedUpdated when the record
+// was modified since it was read.
+var ErrUpdateConflict = errors.New("pocketbase: record was modified concurrently")
+
+// ErrMaxPagesExceeded is returned when a paginated call would fetch more pages than
+// allowed by WithMaxPages, which protects against servers reporting wrong totals.
+var ErrMaxPagesExceeded = errors.New("pocketbase: maximum number of pages exceeded")
+
+// ErrLimitReached is returned by GetAllLogs together with the entries collected so far
+// when more entries match than it collects in memory.
+var ErrLimitReached = errors.New("pocketbase: maximum number of items reached")
+
+// ErrDestructiveOnRemote is returned when a destructive operation such as
+// TruncateCollectionFast targets a remote server without WithAllowDestructiveOnRemote.
+var ErrDestructiveOnRemote = errors.New("pocketbase: destructive operation refused on a remote server")
+
+// ErrInsecureAuth is returned when WithRequireHTTPSForAuth is set and a request carrying
+// the authentication token would be sent over plain HTTP to a host other than localhost.
+var ErrInsecureAuth = errors.New("pocketbase: refusing to send the auth token over plain HTTP")
+
+// ErrSuperuserRequired is returned by the Superuser operations when the client isn't
+// authenticated as a superuser.
+var ErrSuperuserRequired = errors.New("pocketbase: superuser authentication required")
+
+// ErrRequestsCanceled is returned for the requests aborted by Client.CancelAll and the
+// requests made after it until Client.ResumeRequests is called.
+var ErrRequestsCanceled = errors.New("pocketbase: requests canceled")
 
 // APIError represents an error response from the PocketBase API.
 // It implements the error interface and provides structured error information.
@@ -8,6 +48,50 @@ type APIError struct {
 	Status  int            `json:"status"`
 	Message string         `json:"message"`
 	Data    map[string]any `json:"data"`
+
+	// retryAfter holds the wait requested by the server via the Retry-After header.
+	retryAfter time.Duration
+}
+
+// FieldError represents the validation error of a single record field,
+// as returned in APIError.Data.
+type FieldError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// newAPIError creates an APIError from a non-2xx HTTP response.
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{}
+
+	var errResp apiErrorResp
+	if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+		// If we can't decode the error response, create a generic API error
+		apiErr.Status = resp.StatusCode
+		apiErr.Message = resp.Status
+	} else {
+		apiErr.Status = errResp.Status
+		apiErr.Message = errResp.Message
+		apiErr.Data = errResp.Data
+	}
+
+	apiErr.retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+
+	return apiErr
+}
+
+// parseRetryAfter parses a Retry-After header value given either in seconds or as an HTTP date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+	return 0
 }
 
 // Error returns a formatted error string implementing the error interface.
@@ -15,6 +99,51 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("pocketbase API error: %d %s", e.Status, e.Message)
 }
 
+// Verbose returns a multi-line description of the error that includes every field-level
+// error in Data with its code and message, sorted by field name. It is meant for development
+// logging, Error stays terse for production logs.
+//
+// Example:
+//
+//	if apiErr, ok := err.(*pocketbase.APIError); ok {
+//		log.Println(apiErr.Verbose())
+//	}
+//	// pocketbase API error: 400 Failed to create record.
+//	//   slug: validation_not_unique: Value must be unique.
+//	//   title: validation_required: Missing required value.
+func (e *APIError) Verbose() string {
+	var b strings.Builder
+	b.WriteString(e.Error())
+
+	keys := make([]string, 0, len(e.Data))
+	for key := range e.Data {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		b.WriteString("\n  " + key + ": ")
+
+		field, ok := e.Data[key].(map[string]any)
+		code, hasCode := field["code"].(string)
+		message, hasMessage := field["message"].(string)
+		if ok && hasCode && hasMessage && len(field) == 2 {
+			b.WriteString(code + ": " + message)
+			continue
+		}
+
+		// Not a standard field error, dump the value as JSON
+		value, err := json.Marshal(e.Data[key])
+		if err != nil {
+			fmt.Fprintf(&b, "%v", e.Data[key])
+			continue
+		}
+		b.Write(value)
+	}
+
+	return b.String()
+}
+
 // IsNotFound returns true if this is a 404 Not Found error.
 func (e *APIError) IsNotFound() bool {
 	return e.Status == 404
@@ -34,3 +163,60 @@ func (e *APIError) IsForbidden() bool {
 func (e *APIError) IsBadRequest() bool {
 	return e.Status == 400
 }
+
+// IsEmailNotVerified returns true if an authentication request was rejected because
+// the auth record hasn't verified its email yet. PocketBase responds to such requests
+// with 403 Forbidden and the message "Please verify your account first." when the
+// collection only allows verified records to authenticate.
+func (e *APIError) IsEmailNotVerified() bool {
+	return e.Status == 403 && strings.Contains(strings.ToLower(e.Message), "verify")
+}
+
+// IsTooManyRequests returns true if this is a 429 Too Many Requests error.
+func (e *APIError) IsTooManyRequests() bool {
+	return e.Status == 429
+}
+
+// ValidationErrorsAs decodes the field-level errors of an *APIError into a struct T whose
+// fields are FieldError values tagged with the collection field names, for strongly-typed
+// access to validation results. It returns false if err isn't an *APIError, has no
+// field errors or they can't be decoded into T.
+//
+// Example:
+//
+//	type signupErrors struct {
+//		Email    *pocketbase.FieldError `json:"email"`
+//		Password *pocketbase.FieldError `json:"password"`
+//	}
+//
+//	if errs, ok := pocketbase.ValidationErrorsAs[signupErrors](err); ok && errs.Email != nil {
+//		fmt.Println("Email:", errs.Email.Message)
+//	}
+func ValidationErrorsAs[T any](err error) (T, bool) {
+	var result T
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || len(apiErr.Data) == 0 {
+		return result, false
+	}
+
+	data, marshalErr := json.Marshal(apiErr.Data)
+	if marshalErr != nil {
+		return result, false
+	}
+	if json.Unmarshal(data, &result) != nil {
+		return result, false
+	}
+
+	return result, true
+}
+
+// hasFieldCode returns true if any field-level error in Data has the given code.
+func (e *APIError) hasFieldCode(code string) bool {
+	for _, value := range e.Data {
+		if field, ok := value.(map[string]any); ok && field["code"] == code {
+			return true
+		}
+	}
+	return false
+}
@@ -79,15 +79,12 @@ func CreateRecordExample() {
 		fmt.Printf("  Bio: %s\n", createdProfile["bio"])
 
 		// Show expanded relations if present
-		if expandData, ok := createdProfile["expand"]; ok {
-			if expandMap, ok := expandData.(pocketbase.Record); ok {
-				if user, ok := expandMap["user"]; ok {
-					fmt.Printf("  Associated User: %v\n", user)
-				}
-				if interests, ok := expandMap["interests"]; ok {
-					fmt.Printf("  Interests: %v\n", interests)
-				}
-			}
+		if user := createdProfile.Expand("user"); user != nil {
+			fmt.Printf("  Associated User: %v\n", user)
+		}
+		// interests is a multi-relation field, expanded as a list
+		for _, interest := range createdProfile.ExpandList("interests") {
+			fmt.Printf("  Interest: %v\n", interest)
 		}
 	}
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"time"
 
 	"github.com/0x113/pocketbase-go"
@@ -54,17 +55,19 @@ func SuperuserExample() {
 
 	// Impersonate user for 30 minutes (1800 seconds)
 	impersonateResult, err := superuserClient.Impersonate(ctx, "users", userID, 1800,
-		pocketbase.WithExpand("profile"),
-		pocketbase.WithFields("id", "email", "username", "profile"))
+		pocketbase.WithImpersonateQuery(
+			pocketbase.WithExpand("profile"),
+			pocketbase.WithFields("id", "email", "username", "profile")))
 	if err != nil {
 		fmt.Printf("[ERROR] Impersonation failed: %v\n", err)
 	} else {
 		fmt.Printf("[SUCCESS] Successfully impersonated user: %v\n", impersonateResult.Record["email"])
 		fmt.Printf("[SUCCESS] Impersonation token: %.50s...\n", impersonateResult.Token)
 
-		// Create a new client with the impersonation token
-		impersonatedClient := CreateClient("http://localhost:8090")
-		impersonatedClient.SetToken(impersonateResult.Token)
+		// Create a new client with the impersonation token, configured like CreateClient
+		impersonatedClient := impersonateResult.NewClient("http://localhost:8090",
+			pocketbase.WithHTTPClient(&http.Client{Timeout: 10 * time.Second}),
+			pocketbase.WithUserAgent("PocketBase-Go-Example/1.0"))
 
 		// Now make requests as the impersonated user
 		fmt.Println("\nMaking requests as impersonated user...")
@@ -97,15 +97,11 @@ func UpdateRecordExample() {
 		fmt.Printf("  Featured: %t\n", updatedPostWithExpand["featured"])
 
 		// Show expanded relations if present
-		if expandData, ok := updatedPostWithExpand["expand"]; ok {
-			if expandMap, ok := expandData.(pocketbase.Record); ok {
-				if author, ok := expandMap["author"]; ok {
-					fmt.Printf("  Author: %v\n", author)
-				}
-				if category, ok := expandMap["category"]; ok {
-					fmt.Printf("  Category: %v\n", category)
-				}
-			}
+		if author := updatedPostWithExpand.Expand("author"); author != nil {
+			fmt.Printf("  Author: %v\n", author)
+		}
+		if category := updatedPostWithExpand.Expand("category"); category != nil {
+			fmt.Printf("  Category: %v\n", category)
 		}
 	}
 
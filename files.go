@@ -5,12 +5,18 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
+	"net/url"
 	"os"
+	"strconv"
 )
 
 // CreateRecordWithFiles creates a new record with file uploads in the specified collection.
 // The fileUploads parameter should contain the file upload configurations and regular form data.
 //
+// The HTTP client timeout covers the whole upload. For large files, pass a context with a
+// deadline: when it is later than the client timeout, the deadline is used instead.
+//
 // Example:
 //
 //	file1, _ := os.Open("document1.pdf")
@@ -34,7 +40,7 @@ func (c *Client) CreateRecordWithFiles(ctx context.Context, collection string, f
 		opt(options)
 	}
 
-	endpoint := fmt.Sprintf("/api/collections/%s/records", collection)
+	endpoint := fmt.Sprintf("/api/collections/%s/records", url.PathEscape(collection))
 
 	var createdRecord Record
 	err := c.doRequest(ctx, "POST", endpoint, options, &createdRecord)
@@ -42,11 +48,12 @@ func (c *Client) CreateRecordWithFiles(ctx context.Context, collection string, f
 		return nil, err
 	}
 
-	return createdRecord, nil
+	return c.applyRecordHook(createdRecord), nil
 }
 
 // UpdateRecordWithFiles updates an existing record with file uploads in the specified collection.
 // The fileUploads parameter should contain the file upload configurations and regular form data.
+// As with CreateRecordWithFiles, a context deadline later than the HTTP client timeout takes precedence.
 //
 // Example:
 //
@@ -75,7 +82,7 @@ func (c *Client) UpdateRecordWithFiles(ctx context.Context, collection, recordID
 		opt(options)
 	}
 
-	endpoint := fmt.Sprintf("/api/collections/%s/records/%s", collection, recordID)
+	endpoint := fmt.Sprintf("/api/collections/%s/records/%s", url.PathEscape(collection), url.PathEscape(recordID))
 
 	var updatedRecord Record
 	err := c.doRequest(ctx, "PATCH", endpoint, options, &updatedRecord)
@@ -83,7 +90,7 @@ func (c *Client) UpdateRecordWithFiles(ctx context.Context, collection, recordID
 		return nil, err
 	}
 
-	return updatedRecord, nil
+	return c.applyRecordHook(updatedRecord), nil
 }
 
 // CreateFileData creates a FileData struct from an io.Reader
@@ -123,3 +130,194 @@ func CreateFileDataFromFile(filepath string) (FileData, error) {
 		Size:     stat.Size(),
 	}, nil
 }
+
+// GetFileURL returns the URL of a file stored in a record file field.
+// Files of protected fields additionally require a "token" query parameter with a
+// file token, see GetFileToken.
+//
+// Example:
+//
+//	for _, name := range record.FileNames("attachments") {
+//		fmt.Println(client.GetFileURL("documents", record["id"].(string), name))
+//	}
+func (c *Client) GetFileURL(collection, recordID, filename string) string {
+	return fmt.Sprintf("%s/api/files/%s/%s/%s", c.baseURL(),
+		url.PathEscape(collection), url.PathEscape(recordID), url.PathEscape(filename))
+}
+
+// DownloadFile downloads a file stored in a record file field. The caller must close
+// the returned reader. With WithRange, only part of the file is downloaded, which the
+// server answers with 206 Partial Content; an error is returned if the server ignores
+// the range, so a resumed download can't be corrupted by receiving the whole file.
+//
+// Example:
+//
+//	// Resume an interrupted download
+//	offset, _ := out.Seek(0, io.SeekEnd)
+//	body, err := client.DownloadFile(ctx, "documents", "RECORD_ID", "report.pdf",
+//		pocketbase.WithRange(offset, -1))
+//	if err != nil {
+//		return err
+//	}
+//	defer body.Close()
+//	_, err = io.Copy(out, body)
+func (c *Client) DownloadFile(ctx context.Context, collection, recordID, filename string, opts ...DownloadOption) (io.ReadCloser, error) {
+	if c.initErr != nil {
+		return nil, c.initErr
+	}
+
+	options := &DownloadOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	ctx, stop, err := c.requestContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(c.traceContext(ctx), "GET", c.GetFileURL(collection, recordID, filename), nil)
+	if err != nil {
+		stop()
+		return nil, fmt.Errorf("failed to create download request: %w", err)
+	}
+
+	c.setRequestHeaders(ctx, req)
+	if err := c.setAuthHeader(req); err != nil {
+		stop()
+		return nil, err
+	}
+	if options.HasRange {
+		rangeHeader := "bytes=" + strconv.FormatInt(options.RangeStart, 10) + "-"
+		if options.RangeEnd >= 0 {
+			rangeHeader += strconv.FormatInt(options.RangeEnd, 10)
+		}
+		req.Header.Set("Range", rangeHeader)
+	}
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		stop()
+		return nil, canceledError(ctx, fmt.Errorf("failed to download file: %w", err))
+	}
+
+	c.checkRedirect(req, resp)
+	c.observeRateLimit(resp)
+
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		if options.HasRange {
+			resp.Body.Close()
+			stop()
+			return nil, fmt.Errorf("server ignored the range request for %s", filename)
+		}
+	default:
+		defer stop()
+		defer resp.Body.Close()
+		return nil, newAPIError(resp)
+	}
+
+	// The request context must outlive this call until the body is read
+	return &stopOnCloseBody{ReadCloser: resp.Body, stop: stop}, nil
+}
+
+// GetFileToken returns a short-lived token granting the authenticated record access to
+// protected files. It is passed as the "token" query parameter of the file URL.
+//
+// Example:
+//
+//	token, err := client.GetFileToken(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	fileURL := client.GetFileURL("documents", recordID, filename) + "?token=" + url.QueryEscape(token)
+func (c *Client) GetFileToken(ctx context.Context) (string, error) {
+	var resp struct {
+		Token string `json:"token"`
+	}
+	if err := c.doRequest(ctx, "POST", "/api/files/token", nil, &resp); err != nil {
+		return "", err
+	}
+	return resp.Token, nil
+}
+
+// GetRecordWithFileURLs fetches a record like GetRecord and replaces the filenames of its
+// file fields with absolute file URLs, e.g. for rendering templates. Single file fields
+// become a URL string and multi-file fields a list of URLs. The URLs of protected file
+// fields include a file token, requested only when the record has protected files.
+//
+// Pass the file fields with WithFileFields and WithProtectedFileFields, e.g. when
+// rendering as a regular user. When neither is given, the file fields are read from the
+// collection schema instead, which requires superuser authentication. Query options for
+// the record request are passed with WithFileURLQuery.
+//
+// Example:
+//
+//	post, err := client.GetRecordWithFileURLs(ctx, "posts", "RECORD_ID",
+//		pocketbase.WithFileFields("cover"),
+//		pocketbase.WithProtectedFileFields("attachments"))
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Printf(`<img src="%s">`, post["cover"])
+func (c *Client) GetRecordWithFileURLs(ctx context.Context, collection, recordID string, opts ...FileURLOption) (Record, error) {
+	options := &FileURLOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var fields []FieldDef
+	if len(options.FileFields) > 0 || len(options.ProtectedFileFields) > 0 {
+		for _, name := range options.FileFields {
+			fields = append(fields, FieldDef{Name: name, Type: "file"})
+		}
+		for _, name := range options.ProtectedFileFields {
+			fields = append(fields, FieldDef{Name: name, Type: "file", Options: map[string]any{"protected": true}})
+		}
+	} else {
+		var err error
+		if fields, err = c.collectionFields(ctx, collection); err != nil {
+			return nil, err
+		}
+	}
+
+	query := func(opts *QueryOptions) { *opts = options.QueryOptions }
+	record, err := c.GetRecord(ctx, collection, recordID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var token string
+	for _, field := range fields {
+		if field.Type != "file" {
+			continue
+		}
+		filenames := record.FileNames(field.Name)
+		if len(filenames) == 0 {
+			continue
+		}
+
+		query := ""
+		if protected, _ := field.Options["protected"].(bool); protected {
+			if token == "" {
+				if token, err = c.GetFileToken(ctx); err != nil {
+					return nil, fmt.Errorf("failed to get file token: %w", err)
+				}
+			}
+			query = "?token=" + url.QueryEscape(token)
+		}
+
+		urls := make([]any, len(filenames))
+		for i, filename := range filenames {
+			urls[i] = c.GetFileURL(collection, recordID, filename) + query
+		}
+		if _, multiple := record[field.Name].([]any); multiple {
+			record[field.Name] = urls
+		} else {
+			record[field.Name] = urls[0]
+		}
+	}
+
+	return record, nil
+}
@@ -0,0 +1,171 @@
+package pocketbase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateTimeLayout is the datetime format used by PocketBase in filters and record fields.
+const dateTimeLayout = "2006-01-02 15:04:05.000Z"
+
+// filterPlaceholder matches {:name} placeholders in filter expressions.
+var filterPlaceholder = regexp.MustCompile(`\{:(\w+)\}`)
+
+// fieldNamePattern matches valid field names and dot paths (e.g. "author.email").
+var fieldNamePattern = regexp.MustCompile(`^[\w.]+$`)
+
+// Macro is a PocketBase filter datetime macro. Macro params are emitted unquoted by Filter,
+// unlike strings which are always quoted as literals.
+type Macro string
+
+// Datetime macros supported in PocketBase filters, all evaluated in UTC.
+const (
+	MacroNow        Macro = "@now"
+	MacroSecond     Macro = "@second"
+	MacroMinute     Macro = "@minute"
+	MacroHour       Macro = "@hour"
+	MacroWeekday    Macro = "@weekday"
+	MacroDay        Macro = "@day"
+	MacroMonth      Macro = "@month"
+	MacroYear       Macro = "@year"
+	MacroYesterday  Macro = "@yesterday"
+	MacroTomorrow   Macro = "@tomorrow"
+	MacroTodayStart Macro = "@todayStart"
+	MacroTodayEnd   Macro = "@todayEnd"
+	MacroMonthStart Macro = "@monthStart"
+	MacroMonthEnd   Macro = "@monthEnd"
+	MacroYearStart  Macro = "@yearStart"
+	MacroYearEnd    Macro = "@yearEnd"
+)
+
+// Filter builds a filter expression by replacing {:name} placeholders in expr with the
+// corresponding params values, safely quoted and escaped. This prevents user input from
+// breaking out of string literals and changing the meaning of the filter.
+//
+// Strings are single quoted with quotes escaped, numbers and booleans are emitted as-is,
+// nil becomes null and time.Time values are formatted as PocketBase datetimes.
+// Macro values such as MacroNow are emitted bare, so only pass the predefined macros,
+// never a Macro converted from user input. Other values are encoded as JSON strings. Placeholders without a param are left unchanged.
+//
+// Example:
+//
+//	filter := pocketbase.Filter("title ~ {:title} && created > {:since} && expires > {:now}", map[string]any{
+//		"title": userInput,
+//		"since": time.Now().Add(-24 * time.Hour),
+//		"now":   pocketbase.MacroNow,
+//	})
+func Filter(expr string, params map[string]any) string {
+	return filterPlaceholder.ReplaceAllStringFunc(expr, func(placeholder string) string {
+		name := placeholder[2 : len(placeholder)-1]
+		value, ok := params[name]
+		if !ok {
+			return placeholder
+		}
+		return filterValue(value)
+	})
+}
+
+// ValidateFilter checks that filter is accepted by PocketBase for the collection by sending
+// a minimal probe request (a single record ID, without counting the total). It returns the
+// *APIError describing the problem when the filter is rejected, typically a 400, or nil
+// when it is accepted. This gives query builders early feedback before running the query.
+//
+// Example:
+//
+//	if err := client.ValidateFilter(ctx, "posts", userFilter); err != nil {
+//		var apiErr *pocketbase.APIError
+//		if errors.As(err, &apiErr) && apiErr.IsBadRequest() {
+//			return fmt.Errorf("invalid filter: %s", apiErr.Message)
+//		}
+//		return err
+//	}
+func (c *Client) ValidateFilter(ctx context.Context, collection, filter string) error {
+	params := url.Values{}
+	params.Set("page", "1")
+	params.Set("perPage", "1")
+	params.Set("skipTotal", "1")
+	params.Set("fields", "id")
+	params.Set("filter", filter)
+
+	return c.doRequest(ctx, "GET", recordsEndpoint(collection)+"?"+params.Encode(), nil, nil)
+}
+
+// JSONPath returns the filter operand addressing a value nested in a json field,
+// e.g. JSONPath("meta", "author", "name") returns "meta.author.name". Array elements are
+// addressed by their index. Keys must be made of letters, digits and underscores, as
+// PocketBase can't address other keys in filters. Combine it with Filter for the value.
+//
+// PocketBase can filter on nested json values, but a json field can only be updated as
+// a whole, see Record.SetJSONPath.
+//
+// Example:
+//
+//	filter := pocketbase.Filter(pocketbase.JSONPath("meta", "author", "name")+" = {:name}",
+//		map[string]any{"name": "Alice"})
+//	// meta.author.name = 'Alice'
+func JSONPath(field string, keys ...string) string {
+	return strings.Join(append([]string{field}, keys...), ".")
+}
+
+// filterValue formats a value as a filter literal.
+func filterValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return quoteFilterString(v)
+	case Macro:
+		return string(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return quoteFilterString(formatDateTime(v))
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return quoteFilterString(fmt.Sprint(v))
+		}
+		return quoteFilterString(string(encoded))
+	}
+}
+
+// quoteFilterString single quotes a string for use in a filter, escaping embedded quotes.
+func quoteFilterString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "\\'") + "'"
+}
+
+// formatDateTime formats t in the PocketBase datetime format (always UTC).
+func formatDateTime(t time.Time) string {
+	return t.UTC().Format(dateTimeLayout)
+}
+
+// andFilters combines the non-empty filter expressions with "&&",
+// wrapping each one in parentheses so that operator precedence is preserved.
+func andFilters(filters ...string) string {
+	var parts []string
+	for _, filter := range filters {
+		if filter != "" {
+			parts = append(parts, filter)
+		}
+	}
+
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	for i, part := range parts {
+		parts[i] = "(" + part + ")"
+	}
+	return strings.Join(parts, " && ")
+}
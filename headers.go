@@ -0,0 +1,74 @@
+package pocketbase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Headers is a set of HTTP headers sent with every request, see WithHeaders.
+type Headers map[string]string
+
+// HeadersFromStruct builds headers from the fields of a struct (or pointer to struct)
+// tagged with `header:"Name"`. Values are formatted with fmt.Sprint. Like with json tags,
+// the omitempty option skips zero values, and untagged or unexported fields are ignored.
+//
+// Example:
+//
+//	type GatewayHeaders struct {
+//		Tenant  string `header:"X-Tenant"`
+//		Region  string `header:"X-Region,omitempty"`
+//		Version int    `header:"X-Api-Version"`
+//	}
+//
+//	headers, err := pocketbase.HeadersFromStruct(GatewayHeaders{Tenant: "acme", Version: 2})
+//	if err != nil {
+//		return err
+//	}
+//	client := pocketbase.NewClient("http://localhost:8090", pocketbase.WithHeaders(headers))
+func HeadersFromStruct(v any) (Headers, error) {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil, fmt.Errorf("headers struct is nil")
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("headers must be a struct, got %T", v)
+	}
+
+	headers := Headers{}
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		tag, ok := field.Tag.Lookup("header")
+		if !ok || !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		if options == "omitempty" && value.Field(i).IsZero() {
+			continue
+		}
+
+		headers[name] = fmt.Sprint(value.Field(i).Interface())
+	}
+
+	return headers, nil
+}
+
+// setRequestHeaders sets the custom headers configured with WithHeader and WithHeaders
+// and the User-Agent on a request. Headers managed by the client, like the
+// authentication token and Content-Type, are set afterwards and take precedence.
+func (c *Client) setRequestHeaders(ctx context.Context, req *http.Request) {
+	for name, values := range c.headers {
+		req.Header[name] = slices.Clone(values)
+	}
+	req.Header.Set("User-Agent", c.requestUserAgent(ctx))
+}
@@ -0,0 +1,108 @@
+package pocketbase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	// healthEndpoint is the lightweight PocketBase health check endpoint.
+	healthEndpoint = "/api/health"
+	// versionHeader is the response header checked for the server version.
+	versionHeader = "X-PocketBase-Version"
+)
+
+// ErrUnknownVersion is returned by ServerVersion when the server doesn't expose its version.
+var ErrUnknownVersion = errors.New("pocketbase: server version is unknown")
+
+// healthResp represents the response structure from the health endpoint.
+type healthResp struct {
+	Data struct {
+		Version string `json:"version"`
+	} `json:"data"`
+}
+
+// WarmUp sends a single request to the PocketBase health endpoint, which establishes
+// a pooled connection (including the TLS handshake) before the first real request.
+// This trims the latency of that first request, e.g. right after a service starts.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("https://pb.example.com")
+//	if err := client.WarmUp(ctx); err != nil {
+//		log.Printf("PocketBase is not reachable yet: %v", err)
+//	}
+func (c *Client) WarmUp(ctx context.Context) error {
+	return c.doRequest(ctx, "GET", healthEndpoint, nil, nil)
+}
+
+// ServerVersion returns the version of the PocketBase server, for enabling features that
+// only exist on some versions (e.g. batch requests or OTP). The version is read from the
+// X-PocketBase-Version response header or the "version" field of the health endpoint data.
+// Stock PocketBase doesn't expose its version, so it is usually set by a hook or a proxy;
+// when it's missing, an error wrapping ErrUnknownVersion is returned.
+//
+// Example:
+//
+//	version, err := client.ServerVersion(ctx)
+//	if errors.Is(err, pocketbase.ErrUnknownVersion) {
+//		// Fall back to features supported by every version
+//	}
+func (c *Client) ServerVersion(ctx context.Context) (string, error) {
+	if c.initErr != nil {
+		return "", c.initErr
+	}
+
+	ctx, stop, err := c.requestContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer stop()
+
+	resp, err := c.sendRequest(ctx, "GET", c.baseURL()+healthEndpoint, nil, c.contentType)
+	if err != nil {
+		return "", canceledError(ctx, fmt.Errorf("failed to execute request: %w", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", newAPIError(resp)
+	}
+
+	if version := resp.Header.Get(versionHeader); version != "" {
+		io.Copy(io.Discard, resp.Body)
+		return version, nil
+	}
+
+	var health healthResp
+	if err := c.decodeResponse(resp, &health); err != nil {
+		return "", err
+	}
+	if health.Data.Version == "" {
+		return "", fmt.Errorf("%w: not exposed by %s", ErrUnknownVersion, c.baseURL())
+	}
+
+	return health.Data.Version, nil
+}
+
+// keepAlive pings the health endpoint at the given interval so that the pooled
+// connection isn't dropped by load balancers closing idle connections.
+// It stops when the client background context is canceled.
+func (c *Client) keepAlive(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.background.Done():
+			return
+		case <-ticker.C:
+			// Failures are ignored, the next real request reports them
+			c.WarmUp(c.background)
+		}
+	}
+}
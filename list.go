@@ -0,0 +1,92 @@
+package pocketbase
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// ListResultOf represents a single page of a list request, with the items decoded into T.
+type ListResultOf[T any] struct {
+	Page       int `json:"page"`
+	PerPage    int `json:"perPage"`
+	TotalItems int `json:"totalItems"`
+	TotalPages int `json:"totalPages"`
+	Items      []T `json:"items"`
+}
+
+// ListResult represents a single page of records with its pagination metadata.
+type ListResult = ListResultOf[Record]
+
+// GetList fetches a single page of records from a collection together with the
+// pagination metadata, for callers that paginate themselves (e.g. to render page links).
+// Use GetAllRecords to fetch every page at once.
+//
+// Example:
+//
+//	result, err := client.GetList(ctx, "posts", 2, 20, pocketbase.WithSort("-created"))
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Printf("Page %d of %d (%d posts)", result.Page, result.TotalPages, result.TotalItems)
+func (c *Client) GetList(ctx context.Context, collection string, page, perPage int, opts ...ListOption) (*ListResult, error) {
+	return GetListAs[Record](ctx, c, collection, page, perPage, opts...)
+}
+
+// GetListAs fetches a single page of records from a collection like GetList, decoding
+// the items into T, typically a struct with json tags matching the collection fields.
+// A page below 1 fetches the first page and a perPage of 0 uses the client default.
+// With WithStrictDecoding, item fields missing from T are reported as errors.
+//
+// Example:
+//
+//	type Post struct {
+//		ID    string `json:"id"`
+//		Title string `json:"title"`
+//	}
+//
+//	result, err := pocketbase.GetListAs[Post](ctx, client, "posts", 1, 50)
+//	if err != nil {
+//		return err
+//	}
+//	for _, post := range result.Items {
+//		fmt.Println(post.Title)
+//	}
+func GetListAs[T any](ctx context.Context, c *Client, collection string, page, perPage int, opts ...ListOption) (*ListResultOf[T], error) {
+	options := c.newRecordListOptions(opts...)
+	ctx = withRequestUserAgent(ctx, options.UserAgent)
+
+	options.Page = max(page, 1)
+	if perPage > 0 {
+		options.PerPage = min(perPage, maxPerPage)
+	}
+
+	params, err := c.listParams(options, options.Page)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp ListResultOf[json.RawMessage]
+	err = c.doRequest(ctx, "GET", recordsEndpoint(collection)+"?"+params.Encode(), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ListResultOf[T]{
+		Page:       resp.Page,
+		PerPage:    resp.PerPage,
+		TotalItems: resp.TotalItems,
+		TotalPages: resp.TotalPages,
+		Items:      make([]T, len(resp.Items)),
+	}
+	for i, item := range resp.Items {
+		if err := c.decodeTyped(item, &result.Items[i]); err != nil {
+			return nil, err
+		}
+		// Records returned by GetList go through the record hook like the other methods
+		if record, ok := any(&result.Items[i]).(*Record); ok {
+			*record = c.applyRecordHook(*record)
+		}
+	}
+
+	return result, nil
+}
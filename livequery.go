@@ -0,0 +1,205 @@
+package pocketbase
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// LiveCollection is an in-memory copy of the records of a collection kept up to date with
+// realtime events, see Client.LiveQuery. It is safe for concurrent use.
+type LiveCollection struct {
+	client     *Client
+	collection string
+	opts       []ListOption
+
+	mu      sync.RWMutex
+	ids     []string // Record IDs in the order records were loaded or created
+	records map[string]Record
+
+	changes chan struct{}
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// LiveQuery loads the records of a collection matching the list options and keeps them up
+// to date as realtime create, update and delete events arrive. Records returns the current
+// records and Changes notifies when they change, e.g. to re-render a dashboard.
+//
+// The subscription is established before the records are loaded, like with
+// SubscribeWithSnapshot. When the realtime connection is lost, the live query subscribes
+// again and reloads the records, so changes missed while disconnected are picked up.
+// It runs until ctx is canceled, Close is called or the client is closed.
+//
+// The filter, expand and fields options are also applied to the subscription. PocketBase
+// only sends the events of records matching the filter, so a record updated to no longer
+// match it stays in the cache with its last matching values until the next reload.
+//
+// Example:
+//
+//	live, err := client.LiveQuery(ctx, "orders", pocketbase.WithFilter("status='open'"))
+//	if err != nil {
+//		return err
+//	}
+//	defer live.Close()
+//
+//	render(live.Records())
+//	for range live.Changes() {
+//		render(live.Records())
+//	}
+func (c *Client) LiveQuery(ctx context.Context, collection string, opts ...ListOption) (*LiveCollection, error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	initial, conn, err := c.subscribeWithSnapshot(ctx, collection, opts...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	lc := &LiveCollection{
+		client:     c,
+		collection: collection,
+		opts:       opts,
+		changes:    make(chan struct{}, 1),
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+	lc.reset(initial)
+
+	stopOnClose := context.AfterFunc(c.background, cancel)
+	go func() {
+		defer stopOnClose()
+		lc.run(ctx, conn)
+	}()
+
+	return lc, nil
+}
+
+// Records returns the current records, in the order they were loaded followed by the
+// records created since. The records are copies, modifying them doesn't affect the cache.
+func (lc *LiveCollection) Records() []Record {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+
+	records := make([]Record, len(lc.ids))
+	for i, id := range lc.ids {
+		records[i] = maps.Clone(lc.records[id])
+	}
+	return records
+}
+
+// Changes returns a channel receiving a notification when the records change. Changes made
+// while a notification is pending are coalesced into it, so call Records to get the
+// current state. The channel is closed when the live query stops.
+func (lc *LiveCollection) Changes() <-chan struct{} {
+	return lc.changes
+}
+
+// Close stops the live query and removes its subscription. It waits until the realtime
+// connection is closed.
+func (lc *LiveCollection) Close() {
+	lc.cancel()
+	<-lc.done
+}
+
+// run applies the events of conn to the cache, subscribing again and reloading the
+// records when the connection is lost, until ctx is done.
+func (lc *LiveCollection) run(ctx context.Context, conn *RealtimeConnection) {
+	defer close(lc.done)
+	defer close(lc.changes)
+
+	for {
+		for event := range conn.Events() {
+			lc.apply(event)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		initial, reconnected, err := lc.reconnect(ctx)
+		if err != nil {
+			return
+		}
+		conn = reconnected
+		lc.reset(initial)
+		lc.notify()
+	}
+}
+
+// maxReconnectBackoffAttempt caps the attempt number passed to the backoff between
+// reconnect attempts, so a long outage keeps waiting the longest backoff.
+const maxReconnectBackoffAttempt = 10
+
+// reconnect subscribes again and reloads the records, waiting between failed attempts
+// with the client backoff until ctx is done.
+func (lc *LiveCollection) reconnect(ctx context.Context) ([]Record, *RealtimeConnection, error) {
+	for attempt := 1; ; attempt++ {
+		// The attempts are unbounded during an outage, the backoff only needs to reach its cap
+		if err := sleepContext(ctx, lc.client.retryBackoff(min(attempt, maxReconnectBackoffAttempt))); err != nil {
+			return nil, nil, err
+		}
+
+		initial, conn, err := lc.client.subscribeWithSnapshot(ctx, lc.collection, lc.opts...)
+		if err == nil {
+			return initial, conn, nil
+		}
+		lc.client.logger.Warn("pocketbase: live query reconnect failed",
+			"collection", lc.collection, "attempt", attempt, "error", err)
+	}
+}
+
+// reset replaces the cached records.
+func (lc *LiveCollection) reset(records []Record) {
+	lc.mu.Lock()
+	lc.ids = make([]string, 0, len(records))
+	lc.records = make(map[string]Record, len(records))
+	for _, record := range records {
+		id, _ := record["id"].(string)
+		if _, ok := lc.records[id]; !ok {
+			lc.ids = append(lc.ids, id)
+		}
+		lc.records[id] = record
+	}
+	lc.mu.Unlock()
+}
+
+// apply updates the cached records with a realtime event. Like the snapshot records, the
+// event record already went through the record hook, see WithRecordHook.
+func (lc *LiveCollection) apply(event RealtimeEvent) {
+	id, _ := event.Record["id"].(string)
+	if id == "" {
+		return
+	}
+
+	lc.mu.Lock()
+	_, exists := lc.records[id]
+	switch event.Action {
+	case "create", "update":
+		if !exists {
+			lc.ids = append(lc.ids, id)
+		}
+		lc.records[id] = event.Record
+	case "delete":
+		if !exists {
+			lc.mu.Unlock()
+			return
+		}
+		delete(lc.records, id)
+		lc.ids = slices.DeleteFunc(lc.ids, func(other string) bool { return other == id })
+	default:
+		lc.mu.Unlock()
+		return
+	}
+	lc.mu.Unlock()
+
+	lc.notify()
+}
+
+// notify sends a change notification unless one is already pending.
+func (lc *LiveCollection) notify() {
+	select {
+	case lc.changes <- struct{}{}:
+	default:
+	}
+}
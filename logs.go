@@ -0,0 +1,67 @@
+package pocketbase
+
+import "context"
+
+// defaultMaxLogs caps the number of log entries collected by GetAllLogs in memory.
+const defaultMaxLogs = 10000
+
+// logsEndpoint is the superuser endpoint listing the request and application logs.
+const logsEndpoint = "/api/logs"
+
+// GetLogs fetches a single page of log entries. Requires superuser authentication.
+// Log entries are returned as records with the "id", "created", "level", "message"
+// and "data" fields. The list options work like for records, e.g. WithFilter("level > 0")
+// or WithSort("-created").
+//
+// Example:
+//
+//	logs, err := client.GetLogs(ctx, pocketbase.WithFilter("data.status >= 500"), pocketbase.WithSort("-created"))
+//	if err != nil {
+//		return err
+//	}
+//	for _, entry := range logs {
+//		fmt.Printf("%s %s\n", entry["created"], entry["message"])
+//	}
+func (c *Client) GetLogs(ctx context.Context, opts ...ListOption) ([]Record, error) {
+	options := c.newListOptions(opts...)
+	ctx = withRequestUserAgent(ctx, options.UserAgent)
+
+	resp, err := c.getPage(ctx, logsEndpoint, options, options.Page)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Items, nil
+}
+
+// GetAllLogs fetches all log entries matching the list options, automatically handling
+// pagination like GetAllRecords. Requires superuser authentication.
+//
+// Logs can be very large, so at most 10000 entries are collected in memory. When more
+// entries match, the first 10000 are returned with an error wrapping ErrLimitReached.
+// To export the full set, use WithPageCallback to process the entries page by page
+// instead, which removes the cap.
+//
+// Example:
+//
+//	_, err := client.GetAllLogs(ctx,
+//		pocketbase.WithPerPage(500),
+//		pocketbase.WithPageCallback(func(logs []pocketbase.Record) error {
+//			for _, entry := range logs {
+//				if err := encoder.Encode(entry); err != nil {
+//					return err
+//				}
+//			}
+//			return nil
+//		}))
+func (c *Client) GetAllLogs(ctx context.Context, opts ...ListOption) ([]Record, error) {
+	options := c.newListOptions(opts...)
+	ctx = withRequestUserAgent(ctx, options.UserAgent)
+
+	limit := defaultMaxLogs
+	if options.PageCallback != nil {
+		limit = 0
+	}
+
+	return c.getAllItems(ctx, logsEndpoint, options, limit)
+}
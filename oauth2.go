@@ -0,0 +1,172 @@
+package pocketbase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// OAuth2Option represents functional options for AuthWithOAuth2Interactive.
+type OAuth2Option func(*OAuth2Options)
+
+// OAuth2Options holds the settings of the local OAuth2 callback server.
+type OAuth2Options struct {
+	CallbackAddr string                     // Address the callback server listens on (default "127.0.0.1:0", a random port)
+	CallbackPath string                     // Path of the redirect URL (default "/callback")
+	OpenURL      func(authURL string) error // Called with the authorization URL to show to the user
+	CreateData   Record                     // Populates the record when the login creates a new account
+}
+
+// WithOAuth2CallbackAddr sets the address the local callback server listens on,
+// e.g. "127.0.0.1:8085" when the provider only accepts a fixed redirect URL.
+func WithOAuth2CallbackAddr(addr string) OAuth2Option {
+	return func(opts *OAuth2Options) {
+		opts.CallbackAddr = addr
+	}
+}
+
+// WithOAuth2CallbackPath sets the path of the redirect URL served by the local callback server.
+func WithOAuth2CallbackPath(path string) OAuth2Option {
+	return func(opts *OAuth2Options) {
+		opts.CallbackPath = path
+	}
+}
+
+// WithOAuth2URLHandler sets the function called with the authorization URL the user
+// must visit, e.g. to open it in a browser. By default the URL is printed to stderr.
+func WithOAuth2URLHandler(fn func(authURL string) error) OAuth2Option {
+	return func(opts *OAuth2Options) {
+		opts.OpenURL = fn
+	}
+}
+
+// WithOAuth2CreateData sets the data used to populate the record when the OAuth2 login
+// creates a new account, like the createData argument of AuthWithOAuth2Code.
+func WithOAuth2CreateData(data Record) OAuth2Option {
+	return func(opts *OAuth2Options) {
+		opts.CreateData = data
+	}
+}
+
+// AuthWithOAuth2Interactive runs the whole OAuth2 login flow for local tools such as CLIs.
+// It fetches the provider authorization URL, starts a local HTTP server to receive the
+// redirect, prints the authorization URL for the user to open (see WithOAuth2URLHandler),
+// and completes the login with AuthWithOAuth2Code once the authorization code arrives.
+// On success, it stores the authentication token and returns the auth record.
+//
+// The redirect URL is "http://" + the callback address + the callback path, and it must be
+// allowed by the OAuth2 provider app. The call blocks until the redirect is received or
+// ctx is done.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+//	defer cancel()
+//
+//	user, err := client.AuthWithOAuth2Interactive(ctx, "users", "github",
+//		pocketbase.WithOAuth2CallbackAddr("127.0.0.1:8085"))
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Printf("Logged in as %s", user["email"])
+func (c *Client) AuthWithOAuth2Interactive(ctx context.Context, collection, provider string, opts ...OAuth2Option) (Record, error) {
+	options := &OAuth2Options{
+		CallbackAddr: "127.0.0.1:0",
+		CallbackPath: "/callback",
+		OpenURL: func(authURL string) error {
+			_, err := fmt.Fprintf(os.Stderr, "Open the following URL to log in:\n%s\n", authURL)
+			return err
+		},
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	info, err := c.oauth2Provider(ctx, collection, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", options.CallbackAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start OAuth2 callback server: %w", err)
+	}
+	redirectURL := "http://" + listener.Addr().String() + options.CallbackPath
+
+	type callbackResult struct {
+		code string
+		err  error
+	}
+	results := make(chan callbackResult, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(options.CallbackPath, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		var result callbackResult
+		switch {
+		case query.Get("error") != "":
+			result.err = fmt.Errorf("OAuth2 authorization failed: %s", query.Get("error"))
+		case query.Get("state") != info.State:
+			result.err = errors.New("OAuth2 callback state mismatch")
+		case query.Get("code") == "":
+			result.err = errors.New("OAuth2 callback is missing the authorization code")
+		default:
+			result.code = query.Get("code")
+		}
+
+		if result.err != nil {
+			http.Error(w, "Login failed, you can close this window.", http.StatusBadRequest)
+		} else {
+			fmt.Fprint(w, "Login successful, you can close this window.")
+		}
+
+		select {
+		case results <- result:
+		default:
+		}
+	})
+
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer server.Close()
+
+	if err := options.OpenURL(info.AuthURL + url.QueryEscape(redirectURL)); err != nil {
+		return nil, fmt.Errorf("failed to open OAuth2 authorization URL: %w", err)
+	}
+
+	var result callbackResult
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result = <-results:
+	}
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	return c.AuthWithOAuth2Code(ctx, collection, provider, result.code, info.CodeVerifier, redirectURL, options.CreateData)
+}
+
+// oauth2Provider fetches the OAuth2 settings of a provider enabled for an auth collection,
+// including the authorization URL (ending with "redirect_uri=") and the PKCE code verifier.
+func (c *Client) oauth2Provider(ctx context.Context, collection, provider string) (*oauth2ProviderInfo, error) {
+	endpoint := fmt.Sprintf("/api/collections/%s/auth-methods", url.PathEscape(collection))
+
+	var resp authMethodsResp
+	if err := c.doRequest(ctx, "GET", endpoint, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	for _, info := range resp.OAuth2.Providers {
+		if info.Name == provider {
+			return &info, nil
+		}
+	}
+
+	return nil, fmt.Errorf("OAuth2 provider %q is not enabled for collection %q", provider, collection)
+}
@@ -1,7 +1,15 @@
 package pocketbase
 
 import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/http/httptrace"
+	"net/url"
 	"time"
 )
 
@@ -11,6 +19,12 @@ type Option func(*Client)
 // WithHTTPClient sets a custom HTTP client for the PocketBase client.
 // This allows you to configure timeouts, proxies, TLS settings, etc.
 //
+// The provided client is never modified, so it can be shared: options tweaking the HTTP
+// client (WithTimeout) or its transport (e.g. WithIdleTimeout, WithProxy) are applied to
+// a copy once all options have run, so they compose with WithHTTPClient in any order.
+// The copy shares the transport and its connection pool with the provided client unless
+// a transport option is set; see WithHTTPClientClone to copy the transport too.
+//
 // Example:
 //
 //	httpClient := &http.Client{Timeout: 30 * time.Second}
@@ -21,15 +35,38 @@ func WithHTTPClient(httpClient *http.Client) Option {
 	}
 }
 
-// WithTimeout sets a timeout for HTTP requests by creating a new HTTP client
-// with the specified timeout.
+// WithHTTPClientClone uses a copy of httpClient with a clone of its *http.Transport, so
+// later changes to the provided client or its transport don't affect the PocketBase
+// client, and the other way around. The clone has its own connection pool. Custom
+// http.RoundTripper implementations can't be cloned and are shared.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("http://localhost:8090", pocketbase.WithHTTPClientClone(sharedClient))
+func WithHTTPClientClone(httpClient *http.Client) Option {
+	return func(c *Client) {
+		clone := *httpClient
+		if transport, ok := httpClient.Transport.(*http.Transport); ok {
+			clone.Transport = transport.Clone()
+		}
+		c.HTTPClient = &clone
+	}
+}
+
+// WithTimeout sets a timeout for HTTP requests. The timeout caps each whole request,
+// from connecting to reading the response body. Use a context deadline to bound a single
+// call instead, and WithIdleTimeout to control how long pooled connections stay open.
+// It is applied to a copy of the HTTP client after all options have run, so it composes
+// with WithHTTPClient in any order without modifying the provided client.
 //
 // Example:
 //
 //	client := pocketbase.NewClient("http://localhost:8090", pocketbase.WithTimeout(10*time.Second))
 func WithTimeout(timeout time.Duration) Option {
 	return func(c *Client) {
-		c.HTTPClient = &http.Client{Timeout: timeout}
+		c.httpClientOptions = append(c.httpClientOptions, func(httpClient *http.Client) {
+			httpClient.Timeout = timeout
+		})
 	}
 }
 
@@ -43,3 +80,566 @@ func WithUserAgent(userAgent string) Option {
 		c.userAgent = userAgent
 	}
 }
+
+// WithUserAgentSuffix appends an identifier to the User-Agent header instead of replacing it,
+// so requests carry both the SDK and the application identifiers
+// (e.g. "pocketbase-go/1.0 MyApp/2.3"). Use WithUserAgent for a full override.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("http://localhost:8090", pocketbase.WithUserAgentSuffix("MyApp/2.3"))
+func WithUserAgentSuffix(suffix string) Option {
+	return func(c *Client) {
+		c.userAgent += " " + suffix
+	}
+}
+
+// WithHeader sets a custom header sent with every request, e.g. for an API gateway.
+// Headers managed by the client (authentication, Content-Type, User-Agent) can't be
+// overridden this way; use WithTokenHeaderName, WithContentType or WithUserAgent instead.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("http://localhost:8090", pocketbase.WithHeader("X-Tenant", "acme"))
+func WithHeader(name, value string) Option {
+	return func(c *Client) {
+		if c.headers == nil {
+			c.headers = http.Header{}
+		}
+		c.headers.Set(name, value)
+	}
+}
+
+// WithHeaders sets several custom headers sent with every request, like calling
+// WithHeader for each of them. Use HeadersFromStruct to build them from a tagged struct.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("http://localhost:8090", pocketbase.WithHeaders(pocketbase.Headers{
+//		"X-Tenant": "acme",
+//		"X-Region": "eu-west-1",
+//	}))
+func WithHeaders(headers map[string]string) Option {
+	return func(c *Client) {
+		for name, value := range headers {
+			WithHeader(name, value)(c)
+		}
+	}
+}
+
+// WithRequireHTTPSForAuth makes requests carrying the authentication token fail with
+// ErrInsecureAuth when they would be sent over plain HTTP, which would leak the token.
+// Requests to localhost are exempted for local development.
+//
+// Example:
+//
+//	client := pocketbase.NewClient(os.Getenv("PB_URL"), pocketbase.WithRequireHTTPSForAuth())
+func WithRequireHTTPSForAuth() Option {
+	return func(c *Client) {
+		c.requireHTTPSForAuth = true
+	}
+}
+
+// WithSkipTLSForLocalhost relaxes the transport security requirements for local development
+// only: certificate verification is skipped for requests to localhost, 127.0.0.1, ::1 and
+// other loopback addresses, so a self-signed development certificate just works, and like
+// WithRequireHTTPSForAuth the auth token may be sent over plain HTTP to those hosts only.
+// Requests to any other host stay strict: certificates are verified and the auth token is
+// never sent over plain HTTP (ErrInsecureAuth is returned instead), so the same client
+// configuration is safe to ship to production.
+//
+// The localhost requests use a clone of the HTTP client transport, applied after the
+// transport options, so WithMinTLSVersion and the like apply to both. It has no effect
+// when the HTTP client uses a custom http.RoundTripper.
+//
+// Example:
+//
+//	client := pocketbase.NewClient(os.Getenv("PB_URL"), pocketbase.WithSkipTLSForLocalhost())
+func WithSkipTLSForLocalhost() Option {
+	return func(c *Client) {
+		c.skipTLSForLocalhost = true
+		c.requireHTTPSForAuth = true
+	}
+}
+
+// WithAllowDestructiveOnRemote allows destructive operations such as TruncateCollectionFast
+// on servers other than localhost. Without it, they fail with ErrDestructiveOnRemote so
+// that a misconfigured base URL can't wipe production data.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("https://staging.example.com", pocketbase.WithAllowDestructiveOnRemote())
+func WithAllowDestructiveOnRemote() Option {
+	return func(c *Client) {
+		c.allowDestructiveOnRemote = true
+	}
+}
+
+// WithTokenHeaderName sets the name of the header used to send the authentication token.
+// The default is "Authorization". This is useful when the standard header is consumed
+// by an upstream auth gateway sitting in front of PocketBase.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("http://localhost:8090", pocketbase.WithTokenHeaderName("X-Token"))
+func WithTokenHeaderName(name string) Option {
+	return func(c *Client) {
+		c.tokenHeader = name
+	}
+}
+
+// WithJSONMarshaler sets the function used to encode JSON request bodies and form field values.
+// The default is json.Marshal from the standard library. This allows plugging in
+// alternative JSON codecs or pre-processing record bodies before they are sent.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("http://localhost:8090", pocketbase.WithJSONMarshaler(jsoniter.Marshal))
+func WithJSONMarshaler(fn func(any) ([]byte, error)) Option {
+	return func(c *Client) {
+		c.jsonMarshal = fn
+	}
+}
+
+// WithJSONUnmarshaler sets the function used to decode successful JSON response bodies.
+// The default is json.Unmarshal from the standard library.
+// Error responses are always decoded with the standard library.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("http://localhost:8090", pocketbase.WithJSONUnmarshaler(jsoniter.Unmarshal))
+func WithJSONUnmarshaler(fn func([]byte, any) error) Option {
+	return func(c *Client) {
+		c.jsonUnmarshal = fn
+	}
+}
+
+// WithContentType sets the Content-Type header sent with JSON request bodies.
+// The default is "application/json". Multipart file upload requests are not affected.
+// This is useful for strict gateways that require an explicit charset.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("http://localhost:8090",
+//		pocketbase.WithContentType("application/json; charset=utf-8"))
+func WithContentType(contentType string) Option {
+	return func(c *Client) {
+		c.contentType = contentType
+	}
+}
+
+// WithClientTrace attaches an httptrace.ClientTrace to every request, which allows
+// capturing per-request timings such as DNS lookup, connection setup, TLS handshake
+// and time to first response byte. The function is called once per request, so it can
+// return a fresh trace bound to that request's measurements.
+// The trace composes with any trace already present in the context passed to a call:
+// both sets of hooks are invoked.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("http://localhost:8090",
+//		pocketbase.WithClientTrace(func() *httptrace.ClientTrace {
+//			start := time.Now()
+//			return &httptrace.ClientTrace{
+//				GotFirstResponseByte: func() {
+//					log.Printf("time to first byte: %v", time.Since(start))
+//				},
+//			}
+//		}))
+func WithClientTrace(fn func() *httptrace.ClientTrace) Option {
+	return func(c *Client) {
+		c.clientTrace = fn
+	}
+}
+
+// WithDefaultPerPage sets the number of records per page used by list requests such as
+// GetAllRecords when WithPerPage isn't specified. The default is 30 (the PocketBase default).
+// Larger pages reduce the number of round trips for big collections.
+// The value is clamped to the PocketBase maximum of 1000.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("http://localhost:8090", pocketbase.WithDefaultPerPage(500))
+func WithDefaultPerPage(perPage int) Option {
+	return func(c *Client) {
+		if perPage > 0 {
+			c.defaultPerPage = min(perPage, maxPerPage)
+		}
+	}
+}
+
+// WithDefaultListOptions sets list options applied to every record list request, such as
+// GetAllRecords, GetRecords and GetList, before the per-call options. Per-call options
+// override the defaults, except filters: a default filter is combined with the per-call
+// filter using "&&", which makes it suitable for cross-cutting scoping like soft deletes.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("http://localhost:8090",
+//		pocketbase.WithDefaultListOptions(pocketbase.WithFilter("deleted = false")))
+//
+//	// Fetches posts matching (deleted = false) && (status = 'published')
+//	posts, err := client.GetAllRecords(ctx, "posts", pocketbase.WithFilter("status = 'published'"))
+func WithDefaultListOptions(opts ...ListOption) Option {
+	return func(c *Client) {
+		c.defaultListOptions = append(c.defaultListOptions, opts...)
+	}
+}
+
+// WithLogger sets the logger used to report warnings, such as an authentication
+// header being dropped on a redirect. By default nothing is logged.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("http://localhost:8090", pocketbase.WithLogger(slog.Default()))
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithBodyLogging writes the body of every request and response to w, for debugging
+// the exact JSON exchanged with the server. Sensitive fields such as passwords and tokens
+// are redacted, and non-JSON bodies like file uploads are only summarized by their size.
+// Bodies are buffered in memory to be logged, so don't enable it in production.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("http://localhost:8090", pocketbase.WithBodyLogging(os.Stderr))
+func WithBodyLogging(w io.Writer) Option {
+	return func(c *Client) {
+		c.bodyLog = &bodyLogger{w: w}
+	}
+}
+
+// WithRedirectPolicy sets the function that controls how redirects are followed,
+// with the same semantics as http.Client.CheckRedirect. It is applied on top of the
+// configured HTTP client without modifying it.
+//
+// Go's HTTP client drops the Authorization header when a redirect points to a different
+// host, so a PocketBase instance that redirects to a canonical host (or from http to https
+// on another host) will see unauthenticated requests. Configure the BaseURL to the final
+// location, or use a redirect policy to stop following such redirects.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("http://localhost:8090",
+//		pocketbase.WithRedirectPolicy(func(req *http.Request, via []*http.Request) error {
+//			return http.ErrUseLastResponse // never follow redirects
+//		}))
+func WithRedirectPolicy(fn func(req *http.Request, via []*http.Request) error) Option {
+	return func(c *Client) {
+		c.redirectPolicy = fn
+	}
+}
+
+// WithFieldPreset registers a named selection of fields on the client, which can be
+// requested with WithFieldsPreset or WithListFieldsPreset. This centralizes common
+// field selections (e.g. a "card" view) so they can be changed in one place.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("http://localhost:8090",
+//		pocketbase.WithFieldPreset("card", []string{"id", "title", "thumbnail"}))
+//
+//	posts, err := client.GetAllRecords(ctx, "posts", pocketbase.WithListFieldsPreset("card"))
+func WithFieldPreset(name string, fields []string) Option {
+	return func(c *Client) {
+		if c.fieldPresets == nil {
+			c.fieldPresets = make(map[string][]string)
+		}
+		c.fieldPresets[name] = fields
+	}
+}
+
+// WithMinTLSVersion sets the minimum TLS version the client negotiates with the server,
+// e.g. tls.VersionTLS13. By default the Go default is used (currently TLS 1.2).
+// The setting is applied to a clone of the HTTP client transport after all options have
+// run, so it composes with WithHTTPClient and WithTimeout in any order. It has no effect
+// when the HTTP client uses a custom http.RoundTripper.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("https://pb.example.com", pocketbase.WithMinTLSVersion(tls.VersionTLS13))
+func WithMinTLSVersion(version uint16) Option {
+	return func(c *Client) {
+		c.transportOptions = append(c.transportOptions, func(t *http.Transport) {
+			if t.TLSClientConfig == nil {
+				t.TLSClientConfig = &tls.Config{}
+			}
+			t.TLSClientConfig.MinVersion = version
+		})
+	}
+}
+
+// WithTLSClientCert sets a client certificate the client presents to the server during
+// the TLS handshake, for deployments requiring mutual TLS (mTLS). Calling it several times
+// adds several certificates. Like WithMinTLSVersion, it is applied to a clone of the HTTP
+// client transport and composes with the other TLS options.
+//
+// Example:
+//
+//	cert, err := tls.LoadX509KeyPair("client.crt", "client.key")
+//	if err != nil {
+//		return err
+//	}
+//	client := pocketbase.NewClient("https://pb.internal.example.com", pocketbase.WithTLSClientCert(cert))
+func WithTLSClientCert(cert tls.Certificate) Option {
+	return func(c *Client) {
+		c.transportOptions = append(c.transportOptions, func(t *http.Transport) {
+			if t.TLSClientConfig == nil {
+				t.TLSClientConfig = &tls.Config{}
+			}
+			t.TLSClientConfig.Certificates = append(t.TLSClientConfig.Certificates, cert)
+		})
+	}
+}
+
+// WithIdleTimeout sets how long an idle keep-alive connection stays in the connection
+// pool before it is closed (the transport IdleConnTimeout). It doesn't limit how long a
+// request may take, see WithTimeout and context deadlines for that. A short idle timeout
+// avoids reusing connections that a load balancer has already dropped silently.
+// Like WithMinTLSVersion, it is applied to a clone of the HTTP client transport.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("http://localhost:8090", pocketbase.WithIdleTimeout(30*time.Second))
+func WithIdleTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.transportOptions = append(c.transportOptions, func(t *http.Transport) {
+			t.IdleConnTimeout = timeout
+		})
+	}
+}
+
+// WithPreferIPv4 makes the client connect to the server over IPv4 only, by dialing "tcp4"
+// instead of "tcp". It works around networks where IPv6 routes to the server are broken
+// and connections hang until falling back to IPv4. A server reachable only over IPv6
+// can't be reached with it. The dialer already configured on the transport is kept, and
+// like WithIdleTimeout the option is applied to a clone of the HTTP client transport.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("https://pb.example.com", pocketbase.WithPreferIPv4())
+func WithPreferIPv4() Option {
+	return func(c *Client) {
+		c.transportOptions = append(c.transportOptions, func(t *http.Transport) {
+			dial := t.DialContext
+			if dial == nil {
+				dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+			}
+			t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+				if network == "tcp" {
+					network = "tcp4"
+				}
+				return dial(ctx, network, addr)
+			}
+		})
+	}
+}
+
+// WithResponseHeaderTimeout sets how long to wait for the response headers after the
+// request has been sent (the transport ResponseHeaderTimeout). Unlike WithTimeout, it
+// doesn't cap reading the response body, so a slow server is detected at the header stage
+// without cutting short a long DownloadFile stream. It is applied to a clone of the HTTP
+// client transport, like WithIdleTimeout.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("http://localhost:8090", pocketbase.WithResponseHeaderTimeout(10*time.Second))
+func WithResponseHeaderTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.transportOptions = append(c.transportOptions, func(t *http.Transport) {
+			t.ResponseHeaderTimeout = timeout
+		})
+	}
+}
+
+// WithRetry enables retrying failed requests up to maxRetries times, waiting between
+// attempts with an exponential backoff with jitter (see DefaultBackoff and WithBackoff).
+// Only idempotent requests (GET, HEAD, OPTIONS, PUT and DELETE) are retried, on network
+// errors and 502, 503 and 504 responses. Retries stop when the request context is done.
+// By default requests are not retried.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("http://localhost:8090", pocketbase.WithRetry(3))
+func WithRetry(maxRetries int) Option {
+	return func(c *Client) {
+		c.maxRetries = max(maxRetries, 0)
+	}
+}
+
+// WithRetryBudget caps the total time spent on a request across all its attempts when
+// retries are enabled with WithRetry. Once the time elapsed since the first attempt plus
+// the backoff before the next one would exceed the budget, no further retry is made and
+// the last failure is returned, even if retries remain. This avoids slow attempts adding
+// up to far more than expected, e.g. 3 retries of a request timing out after 30s.
+// The budget doesn't interrupt an attempt in progress, use the request context for that.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("http://localhost:8090",
+//		pocketbase.WithRetry(3),
+//		pocketbase.WithRetryBudget(10*time.Second))
+func WithRetryBudget(d time.Duration) Option {
+	return func(c *Client) {
+		c.retryBudget = d
+	}
+}
+
+// WithServerTimeOffset sets how far the server clock is ahead of the local clock
+// (negative when it is behind). The offset is applied when checking token expiry in
+// IsTokenExpired and when scheduling StartAutoRefresh, so a skewed local clock doesn't
+// reject valid tokens or keep using expired ones.
+//
+// Example:
+//
+//	// The local clock is 2 minutes ahead of the server
+//	client := pocketbase.NewClient("http://localhost:8090", pocketbase.WithServerTimeOffset(-2*time.Minute))
+func WithServerTimeOffset(offset time.Duration) Option {
+	return func(c *Client) {
+		c.serverTimeOffset = offset
+	}
+}
+
+// WithBackoff sets the function returning how long to wait before a retry enabled with
+// WithRetry. The retry argument is 1 for the first retry, 2 for the second and so on.
+// The default is DefaultBackoff, exponential backoff with full jitter.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("http://localhost:8090",
+//		pocketbase.WithRetry(5),
+//		pocketbase.WithBackoff(func(retry int) time.Duration {
+//			return time.Duration(retry) * 500 * time.Millisecond
+//		}))
+func WithBackoff(fn func(retry int) time.Duration) Option {
+	return func(c *Client) {
+		c.backoff = fn
+	}
+}
+
+// WithCircuitBreaker stops sending requests for the cooldown period after failureThreshold
+// consecutive failures (network errors, timeouts and 5xx responses), returning ErrCircuitOpen
+// right away instead. After the cooldown a single trial request is sent: if it succeeds
+// requests flow normally again, otherwise the breaker opens for another cooldown. A trial
+// that is canceled or never sent (e.g. ErrInsecureAuth) leaves the breaker open, and the
+// next request becomes the trial. This keeps callers from piling up slow requests while PocketBase is down.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("http://localhost:8090",
+//		pocketbase.WithCircuitBreaker(5, 30*time.Second))
+//
+//	_, err := client.GetRecord(ctx, "posts", "RECORD_ID")
+//	if errors.Is(err, pocketbase.ErrCircuitOpen) {
+//		// PocketBase is known to be down, serve a cached response instead
+//	}
+func WithCircuitBreaker(failureThreshold int, cooldown time.Duration) Option {
+	return func(c *Client) {
+		c.circuitBreaker = &circuitBreaker{
+			threshold: max(failureThreshold, 1),
+			cooldown:  cooldown,
+		}
+	}
+}
+
+// WithKeepAlivePing periodically sends a request to the PocketBase health endpoint,
+// keeping the pooled connection alive through load balancers that drop idle connections.
+// The pings are sent in the background for the lifetime of the client.
+// See also WithIdleTimeout and Client.WarmUp.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("https://pb.example.com", pocketbase.WithKeepAlivePing(30*time.Second))
+func WithKeepAlivePing(interval time.Duration) Option {
+	return func(c *Client) {
+		c.keepAliveInterval = interval
+	}
+}
+
+// WithStrictDecoding makes the generic helpers such as GetListAs fail when a record
+// has a field that the target type doesn't declare, instead of silently ignoring it.
+// This surfaces schema drift between the app types and the collections early, which is
+// useful during development. Records decoded as Record values are not affected.
+// Strict decoding always uses the standard library decoder.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("http://localhost:8090", pocketbase.WithStrictDecoding())
+func WithStrictDecoding() Option {
+	return func(c *Client) {
+		c.strictDecoding = true
+	}
+}
+
+// WithRecordHook sets a function applied to every record returned by the client, e.g. to
+// decrypt a field or compute a derived one in a single place: fetched, listed, created,
+// updated and deleted records, file upload results, batch results, auth records (also the
+// one stored as AuthRecord), the record of Impersonate and the records of realtime events
+// and live queries. It runs after the response was decoded successfully and the record it
+// returns is the one handed to the caller. For WithPageCallback, it runs before the
+// callback.
+//
+// It doesn't apply to the responses of Send, to log entries, to items decoded into other
+// types with the generic helpers such as GetListAs, nor separately to expanded relations
+// nested in a record.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("http://localhost:8090",
+//		pocketbase.WithRecordHook(func(record pocketbase.Record) pocketbase.Record {
+//			record["fullName"] = fmt.Sprintf("%v %v", record["firstName"], record["lastName"])
+//			return record
+//		}))
+func WithRecordHook(fn func(Record) Record) Option {
+	return func(c *Client) {
+		c.recordHook = fn
+	}
+}
+
+// WithProxy routes all requests through the HTTP(S) proxy at proxyURL
+// (e.g. "http://proxy.corp.example:3128"), overriding the proxy environment variables.
+// Like WithMinTLSVersion, it is applied to a clone of the HTTP client transport.
+// If proxyURL can't be parsed, every request fails with an error describing it.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("https://pb.example.com", pocketbase.WithProxy("http://proxy.corp.example:3128"))
+func WithProxy(proxyURL string) Option {
+	return func(c *Client) {
+		u, err := url.Parse(proxyURL)
+		if err == nil && (u.Scheme == "" || u.Host == "") {
+			err = fmt.Errorf("missing scheme or host")
+		}
+		if err != nil {
+			c.initErr = fmt.Errorf("invalid proxy URL %q: %w", proxyURL, err)
+			return
+		}
+
+		c.transportOptions = append(c.transportOptions, func(t *http.Transport) {
+			t.Proxy = http.ProxyURL(u)
+		})
+	}
+}
+
+// WithSharedSuperuserAuth makes AuthenticateAsSuperuser share its token through store
+// with the other clients using the same store. The first client authenticates and the
+// others reuse its token until it is about to expire, when a single client logs in again.
+//
+// Example:
+//
+//	store := pocketbase.NewSuperuserTokenStore()
+//	for _, job := range jobs {
+//		client := pocketbase.NewClient("http://localhost:8090", pocketbase.WithSharedSuperuserAuth(store))
+//		if _, err := client.AuthenticateAsSuperuser(ctx, email, password); err != nil {
+//			return err
+//		}
+//		go job.Run(client)
+//	}
+func WithSharedSuperuserAuth(store *SuperuserTokenStore) Option {
+	return func(c *Client) {
+		c.superuserStore = store
+	}
+}
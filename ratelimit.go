@@ -0,0 +1,95 @@
+package pocketbase
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// resetTimestampThreshold separates X-RateLimit-Reset values holding a number of seconds
+// until the reset from values holding the Unix time of the reset.
+const resetTimestampThreshold = 1_000_000_000
+
+// RateLimitState is the rate limit reported by the server or a proxy in the
+// X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset response headers.
+type RateLimitState struct {
+	Limit     int       // Requests allowed in the current window, -1 when not reported
+	Remaining int       // Requests left in the current window
+	Reset     time.Time // When the window resets, zero when not reported
+	Updated   time.Time // When the headers were received
+}
+
+// WithRateLimitObserver sets a function called with the rate limit state whenever a
+// response carries rate limit headers, so callers can slow down as they approach the
+// limit instead of reacting to 429 responses. It is called synchronously on the request
+// goroutine, so it should return quickly. Responses without the headers are ignored.
+//
+// Example:
+//
+//	client := pocketbase.NewClient("https://pb.example.com",
+//		pocketbase.WithRateLimitObserver(func(state pocketbase.RateLimitState) {
+//			if state.Remaining < 10 {
+//				log.Printf("only %d requests left until %s", state.Remaining, state.Reset)
+//			}
+//		}))
+func WithRateLimitObserver(fn func(RateLimitState)) Option {
+	return func(c *Client) {
+		c.rateLimitObserver = fn
+	}
+}
+
+// RateLimitState returns the rate limit reported by the last response carrying rate limit
+// headers. It returns false when no such response was received yet.
+//
+// Example:
+//
+//	if state, ok := client.RateLimitState(); ok && state.Remaining == 0 {
+//		time.Sleep(time.Until(state.Reset))
+//	}
+func (c *Client) RateLimitState() (RateLimitState, bool) {
+	c.rateLimitMu.Lock()
+	defer c.rateLimitMu.Unlock()
+
+	return c.rateLimit, !c.rateLimit.Updated.IsZero()
+}
+
+// observeRateLimit records the rate limit headers of a response, if any, and passes
+// them to the observer set with WithRateLimitObserver.
+func (c *Client) observeRateLimit(resp *http.Response) {
+	state, ok := parseRateLimit(resp.Header, time.Now())
+	if !ok {
+		return
+	}
+
+	c.rateLimitMu.Lock()
+	c.rateLimit = state
+	c.rateLimitMu.Unlock()
+
+	if c.rateLimitObserver != nil {
+		c.rateLimitObserver(state)
+	}
+}
+
+// parseRateLimit parses the rate limit headers. The X-RateLimit-Remaining header is
+// required; X-RateLimit-Reset is either a number of seconds until the reset or the Unix
+// time of the reset.
+func parseRateLimit(header http.Header, now time.Time) (RateLimitState, bool) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return RateLimitState{}, false
+	}
+
+	state := RateLimitState{Limit: -1, Remaining: remaining, Updated: now}
+	if limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit")); err == nil {
+		state.Limit = limit
+	}
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil && reset >= 0 {
+		if reset >= resetTimestampThreshold {
+			state.Reset = time.Unix(reset, 0)
+		} else {
+			state.Reset = now.Add(time.Duration(reset) * time.Second)
+		}
+	}
+
+	return state, true
+}
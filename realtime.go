@@ -0,0 +1,385 @@
+package pocketbase
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// realtimeUnsubscribeTimeout bounds the request removing the subscriptions
+// of a canceled realtime connection.
+const realtimeUnsubscribeTimeout = 5 * time.Second
+
+// RealtimeEvent represents a record change received from the PocketBase realtime API.
+type RealtimeEvent struct {
+	Topic  string // The subscription topic the event was delivered for (e.g. "posts/*")
+	Action string // "create", "update" or "delete"
+	Record Record
+}
+
+// realtimeMessage represents the payload of a realtime record event.
+type realtimeMessage struct {
+	Action string `json:"action"`
+	Record Record `json:"record"`
+}
+
+// RealtimeConnection is an open realtime connection with its subscriptions.
+type RealtimeConnection struct {
+	client   *Client
+	clientID string
+	topics   []string
+	events   <-chan RealtimeEvent
+	cancel   context.CancelFunc
+}
+
+// Events returns the channel receiving the events of the subscribed topics. It is closed
+// when the connection is closed or lost.
+func (rc *RealtimeConnection) Events() <-chan RealtimeEvent {
+	return rc.events
+}
+
+// ClientID returns the client ID the server assigned to the connection in the PB_CONNECT
+// handshake, which PocketBase also uses to identify the connection in its logs.
+func (rc *RealtimeConnection) ClientID() string {
+	return rc.clientID
+}
+
+// Topics returns the topics the connection is subscribed to.
+func (rc *RealtimeConnection) Topics() []string {
+	return slices.Clone(rc.topics)
+}
+
+// Close removes the subscriptions and closes the connection. The events channel is
+// closed once the connection is closed.
+func (rc *RealtimeConnection) Close() {
+	rc.cancel()
+}
+
+// Reconnect opens a new connection subscribed to the same topics, e.g. after the events
+// channel was closed because the connection was lost. The server assigns the new
+// connection a new client ID.
+func (rc *RealtimeConnection) Reconnect(ctx context.Context) (*RealtimeConnection, error) {
+	rc.cancel()
+	return rc.client.subscribe(ctx, rc.topics...)
+}
+
+// sseEvent represents a single server-sent event read from the realtime stream.
+type sseEvent struct {
+	ID   string
+	Name string
+	Data []byte
+}
+
+// Subscribe opens a realtime connection and subscribes to the given topics.
+// A topic is either "COLLECTION/*" to receive changes of all records in a collection
+// or "COLLECTION/RECORD_ID" to receive changes of a single record.
+// The current authentication token is used to authorize the subscriptions.
+//
+// The returned channel is closed when ctx is canceled or the connection is lost.
+// On cancellation the subscriptions are removed on the server before the connection
+// is closed, so the channel may take a moment to close.
+// The HTTP client timeout is not applied to the realtime connection, use ctx to bound it.
+//
+// Example:
+//
+//	events, err := client.Subscribe(ctx, "posts/*")
+//	if err != nil {
+//		return err
+//	}
+//	for event := range events {
+//		fmt.Printf("%s: %s\n", event.Action, event.Record["id"])
+//	}
+func (c *Client) Subscribe(ctx context.Context, topics ...string) (<-chan RealtimeEvent, error) {
+	conn, err := c.subscribe(ctx, topics...)
+	if err != nil {
+		return nil, err
+	}
+	return conn.events, nil
+}
+
+// SubscribeConnection subscribes to the given topics like Subscribe, returning the
+// connection itself. It exposes the client ID assigned by the server, which helps
+// matching the connection with the server logs, and can be closed or reconnected
+// with the same subscriptions.
+//
+// Example:
+//
+//	conn, err := client.SubscribeConnection(ctx, "posts/*")
+//	if err != nil {
+//		return err
+//	}
+//	log.Printf("realtime client %s connected", conn.ClientID())
+//	for {
+//		for event := range conn.Events() {
+//			fmt.Printf("%s: %s\n", event.Action, event.Record["id"])
+//		}
+//		if ctx.Err() != nil {
+//			return ctx.Err()
+//		}
+//		// The connection was lost, subscribe again to the same topics
+//		if conn, err = conn.Reconnect(ctx); err != nil {
+//			return err
+//		}
+//	}
+func (c *Client) SubscribeConnection(ctx context.Context, topics ...string) (*RealtimeConnection, error) {
+	return c.subscribe(ctx, topics...)
+}
+
+// SubscribeWithSnapshot subscribes to changes of all records in a collection and fetches
+// the current records matching the list options, returning both.
+// The subscription is established before the records are fetched, so changes that happen
+// during the initial fetch are delivered on the events channel instead of being lost.
+// Such changes may already be reflected in the initial records, so events should be
+// applied idempotently (e.g. keyed by record ID).
+//
+// The filter, expand and fields list options, including the client defaults set with
+// WithDefaultListOptions, are also applied to the subscription.
+//
+// Example:
+//
+//	initial, events, err := client.SubscribeWithSnapshot(ctx, "posts",
+//		pocketbase.WithFilter("status='published'"))
+//	if err != nil {
+//		return err
+//	}
+//	render(initial)
+//	for event := range events {
+//		apply(event)
+//	}
+func (c *Client) SubscribeWithSnapshot(ctx context.Context, collection string, opts ...ListOption) ([]Record, <-chan RealtimeEvent, error) {
+	initial, conn, err := c.subscribeWithSnapshot(ctx, collection, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return initial, conn.events, nil
+}
+
+// subscribeWithSnapshot subscribes to the changes of a collection and fetches its current
+// records, see SubscribeWithSnapshot.
+func (c *Client) subscribeWithSnapshot(ctx context.Context, collection string, opts ...ListOption) ([]Record, *RealtimeConnection, error) {
+	// The client default list options apply to both the subscription and the snapshot
+	options := c.newRecordListOptions(opts...)
+	ctx = withRequestUserAgent(ctx, options.UserAgent)
+
+	topic, err := subscriptionTopic(collection+"/*", options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conn, err := c.subscribe(ctx, topic)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	initial, err := c.getAllRecords(ctx, collection, options)
+	if err != nil {
+		conn.Close()
+		// Drain the events channel so the subscription goroutine can exit
+		for range conn.events {
+		}
+		return nil, nil, err
+	}
+
+	return initial, conn, nil
+}
+
+// subscribe opens a realtime connection subscribed to the given topics.
+//
+// When ctx is canceled or the client is closed, the subscriptions are removed with a final
+// subscribe request before the connection is closed, so the server doesn't keep them
+// around until it notices the client is gone.
+func (c *Client) subscribe(ctx context.Context, topics ...string) (*RealtimeConnection, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	stopOnClose := context.AfterFunc(c.background, cancel)
+
+	// The stream outlives ctx until the subscriptions have been removed
+	streamCtx, closeStream := context.WithCancel(context.WithoutCancel(ctx))
+
+	reader, body, clientID, err := c.connectRealtime(streamCtx)
+	if err != nil {
+		stopOnClose()
+		cancel()
+		closeStream()
+		return nil, err
+	}
+
+	if err := c.setSubscriptions(ctx, clientID, topics); err != nil {
+		stopOnClose()
+		cancel()
+		closeStream()
+		body.Close()
+		return nil, err
+	}
+
+	go func() {
+		<-ctx.Done()
+		if streamCtx.Err() == nil {
+			unsubscribeCtx, cancelUnsubscribe := context.WithTimeout(streamCtx, realtimeUnsubscribeTimeout)
+			c.setSubscriptions(unsubscribeCtx, clientID, []string{})
+			cancelUnsubscribe()
+		}
+		closeStream()
+	}()
+
+	events := make(chan RealtimeEvent)
+	go func() {
+		defer close(events)
+		defer body.Close()
+		defer stopOnClose()
+		defer cancel()
+		// The connection is gone when reading stops, there is nothing to unsubscribe from
+		defer closeStream()
+
+		for {
+			ev, err := readSSEEvent(reader)
+			if err != nil {
+				return
+			}
+
+			var msg realtimeMessage
+			if err := json.Unmarshal(ev.Data, &msg); err != nil || msg.Action == "" {
+				// Not a record event (e.g. keep-alive), skip it
+				continue
+			}
+
+			select {
+			case events <- RealtimeEvent{Topic: ev.Name, Action: msg.Action, Record: c.applyRecordHook(msg.Record)}:
+			case <-ctx.Done():
+				// Keep reading until the stream is closed after unsubscribing
+			}
+		}
+	}()
+
+	return &RealtimeConnection{
+		client:   c,
+		clientID: clientID,
+		topics:   slices.Clone(topics),
+		events:   events,
+		cancel:   cancel,
+	}, nil
+}
+
+// setSubscriptions replaces the topics the realtime client is subscribed to.
+// An empty list removes all subscriptions.
+func (c *Client) setSubscriptions(ctx context.Context, clientID string, topics []string) error {
+	reqBody := map[string]any{
+		"clientId":      clientID,
+		"subscriptions": topics,
+	}
+	return c.doRequest(ctx, "POST", "/api/realtime", reqBody, nil)
+}
+
+// connectRealtime opens the realtime event stream and waits for the PB_CONNECT event.
+// It returns a reader positioned after the connect event, the stream body to close
+// when done and the client ID assigned by the server.
+func (c *Client) connectRealtime(ctx context.Context) (*bufio.Reader, io.Closer, string, error) {
+	if c.initErr != nil {
+		return nil, nil, "", c.initErr
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL()+"/api/realtime", nil)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to create realtime request: %w", err)
+	}
+
+	c.setRequestHeaders(ctx, req)
+	req.Header.Set("Accept", "text/event-stream")
+
+	// The realtime connection is long-lived, so the overall HTTP client timeout
+	// must not apply to it
+	httpClient := *c.HTTPClient
+	httpClient.Timeout = 0
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to connect to realtime: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, nil, "", newAPIError(resp)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	ev, err := readSSEEvent(reader)
+	if err != nil {
+		resp.Body.Close()
+		return nil, nil, "", fmt.Errorf("failed to read realtime connect event: %w", err)
+	}
+
+	var connect struct {
+		ClientID string `json:"clientId"`
+	}
+	if ev.Name != "PB_CONNECT" || json.Unmarshal(ev.Data, &connect) != nil || connect.ClientID == "" {
+		resp.Body.Close()
+		return nil, nil, "", fmt.Errorf("unexpected realtime connect event %q", ev.Name)
+	}
+
+	return reader, resp.Body, connect.ClientID, nil
+}
+
+// readSSEEvent reads the next server-sent event from the stream.
+func readSSEEvent(reader *bufio.Reader) (*sseEvent, error) {
+	ev := &sseEvent{}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			// A blank line dispatches the event
+			if ev.ID == "" && ev.Name == "" && len(ev.Data) == 0 {
+				continue
+			}
+			return ev, nil
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "id":
+			ev.ID = value
+		case "event":
+			ev.Name = value
+		case "data":
+			if len(ev.Data) > 0 {
+				ev.Data = append(ev.Data, '\n')
+			}
+			ev.Data = append(ev.Data, value...)
+		}
+	}
+}
+
+// subscriptionTopic appends the filter, expand and fields options to a subscription topic.
+func subscriptionTopic(topic string, options *ListOptions) (string, error) {
+	query := map[string]string{}
+	if options.Filter != "" {
+		query["filter"] = options.Filter
+	}
+	if len(options.Expand) > 0 {
+		query["expand"] = strings.Join(options.Expand, ",")
+	}
+	if len(options.Fields) > 0 {
+		query["fields"] = strings.Join(options.Fields, ",")
+	}
+	if len(query) == 0 {
+		return topic, nil
+	}
+
+	encoded, err := json.Marshal(map[string]any{"query": query})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal subscription options: %w", err)
+	}
+
+	return topic + "?options=" + url.QueryEscape(string(encoded)), nil
+}
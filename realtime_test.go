@@ -0,0 +1,523 @@
+package pocketbase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// mockRealtimeServer mocks the PocketBase realtime API. Events written to the
+// events channel are pushed to the connected client once it has subscribed.
+type mockRealtimeServer struct {
+	*httptest.Server
+	events        chan string
+	subscriptions chan []string
+}
+
+func newMockRealtimeServer(t *testing.T, fallback http.HandlerFunc) *mockRealtimeServer {
+	m := &mockRealtimeServer{
+		events:        make(chan string, 10),
+		subscriptions: make(chan []string, 10),
+	}
+
+	m.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/realtime" {
+			fallback(w, r)
+			return
+		}
+
+		switch r.Method {
+		case "GET":
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "id:client-123\nevent:PB_CONNECT\ndata:{\"clientId\":\"client-123\"}\n\n")
+			w.(http.Flusher).Flush()
+
+			for {
+				select {
+				case event := <-m.events:
+					fmt.Fprint(w, event)
+					w.(http.Flusher).Flush()
+				case <-r.Context().Done():
+					return
+				}
+			}
+		case "POST":
+			var body struct {
+				ClientID      string   `json:"clientId"`
+				Subscriptions []string `json:"subscriptions"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("Failed to decode subscribe body: %v", err)
+			}
+			if body.ClientID != "client-123" {
+				t.Errorf("Expected clientId 'client-123', got '%s'", body.ClientID)
+			}
+
+			m.subscriptions <- body.Subscriptions
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+
+	return m
+}
+
+// recordEvent formats a realtime record event in the server-sent events format.
+func recordEvent(topic, action string, record Record) string {
+	data, _ := json.Marshal(map[string]any{"action": action, "record": record})
+	return fmt.Sprintf("id:client-123\nevent:%s\ndata:%s\n\n", topic, data)
+}
+
+func TestClient_Subscribe(t *testing.T) {
+	server := newMockRealtimeServer(t, http.NotFound)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := NewClient(server.URL)
+	client.SetToken("test-token")
+
+	events, err := client.Subscribe(ctx, "posts/*")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	subscriptions := <-server.subscriptions
+	if len(subscriptions) != 1 || subscriptions[0] != "posts/*" {
+		t.Errorf("Expected subscriptions [posts/*], got %v", subscriptions)
+	}
+
+	server.events <- recordEvent("posts/*", "create", Record{"id": "record-1"})
+
+	event := <-events
+	if event.Topic != "posts/*" {
+		t.Errorf("Expected topic 'posts/*', got '%s'", event.Topic)
+	}
+	if event.Action != "create" {
+		t.Errorf("Expected action 'create', got '%s'", event.Action)
+	}
+	if event.Record["id"] != "record-1" {
+		t.Errorf("Expected record ID 'record-1', got '%v'", event.Record["id"])
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestClient_SubscribeWithSnapshot(t *testing.T) {
+	var server *mockRealtimeServer
+	server = newMockRealtimeServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/collections/posts/records" {
+			t.Errorf("Unexpected path '%s'", r.URL.Path)
+		}
+
+		// Simulate a change that happens while the initial records are being fetched
+		server.events <- recordEvent("posts/*", "update", Record{"id": "record-2", "title": "Changed"})
+
+		response := listResp{
+			Page:       1,
+			PerPage:    30,
+			TotalItems: 2,
+			TotalPages: 1,
+			Items: []Record{
+				{"id": "record-1", "title": "Post 1"},
+				{"id": "record-2", "title": "Post 2"},
+			},
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	})
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := NewClient(server.URL)
+
+	initial, events, err := client.SubscribeWithSnapshot(ctx, "posts", WithFilter("status='published'"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	subscriptions := <-server.subscriptions
+	expectedTopic := `posts/*?options=%7B%22query%22%3A%7B%22filter%22%3A%22status%3D%27published%27%22%7D%7D`
+	if len(subscriptions) != 1 || subscriptions[0] != expectedTopic {
+		t.Errorf("Expected subscriptions [%s], got %v", expectedTopic, subscriptions)
+	}
+
+	if len(initial) != 2 {
+		t.Errorf("Expected 2 initial records, got %d", len(initial))
+	}
+
+	// The change made during the initial fetch must not be lost
+	event := <-events
+	if event.Action != "update" || event.Record["id"] != "record-2" {
+		t.Errorf("Expected update event for 'record-2', got %s for '%v'", event.Action, event.Record["id"])
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestClient_SubscribeUnsubscribesOnCancel(t *testing.T) {
+	server := newMockRealtimeServer(t, http.NotFound)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := NewClient(server.URL)
+
+	events, err := client.Subscribe(ctx, "posts/*")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if subscriptions := <-server.subscriptions; len(subscriptions) != 1 {
+		t.Fatalf("Expected 1 subscription, got %v", subscriptions)
+	}
+
+	cancel()
+	for range events {
+	}
+
+	// The subscriptions are removed before the connection is closed
+	select {
+	case subscriptions := <-server.subscriptions:
+		if len(subscriptions) != 0 {
+			t.Errorf("Expected empty subscriptions on cancel, got %v", subscriptions)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected an unsubscribe request on cancel")
+	}
+}
+
+func TestClient_CloseEndsSubscriptions(t *testing.T) {
+	server := newMockRealtimeServer(t, http.NotFound)
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	events, err := client.Subscribe(context.Background(), "posts/*")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	<-server.subscriptions
+
+	client.Close()
+
+	done := make(chan struct{})
+	go func() {
+		for range events {
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the events channel to be closed by Close")
+	}
+
+	select {
+	case subscriptions := <-server.subscriptions:
+		if len(subscriptions) != 0 {
+			t.Errorf("Expected empty subscriptions on close, got %v", subscriptions)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected an unsubscribe request on close")
+	}
+}
+
+func TestClient_SubscribeConnection(t *testing.T) {
+	server := newMockRealtimeServer(t, http.NotFound)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := NewClient(server.URL)
+
+	conn, err := client.SubscribeConnection(ctx, "posts/*", "users/abc123")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	<-server.subscriptions
+
+	if conn.ClientID() != "client-123" {
+		t.Errorf("Expected client ID 'client-123', got '%s'", conn.ClientID())
+	}
+
+	// Reconnecting subscribes again to the same topics
+	conn, err = conn.Reconnect(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The first connection removes its subscriptions while the new one subscribes
+	for range 2 {
+		subscriptions := <-server.subscriptions
+		if len(subscriptions) != 0 && !slices.Equal(subscriptions, []string{"posts/*", "users/abc123"}) {
+			t.Errorf("Expected the same topics on reconnect, got %v", subscriptions)
+		}
+	}
+	if !slices.Equal(conn.Topics(), []string{"posts/*", "users/abc123"}) {
+		t.Errorf("Expected topics to be kept, got %v", conn.Topics())
+	}
+
+	conn.Close()
+	for range conn.Events() {
+	}
+}
+
+func TestClient_LiveQuery(t *testing.T) {
+	server := newMockRealtimeServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/collections/orders/records" {
+			t.Errorf("Unexpected path '%s'", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(listResp{
+			Page:       1,
+			PerPage:    30,
+			TotalItems: 2,
+			TotalPages: 1,
+			Items: []Record{
+				{"id": "order-1", "status": "open"},
+				{"id": "order-2", "status": "open"},
+			},
+		})
+	})
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := NewClient(server.URL)
+
+	live, err := client.LiveQuery(ctx, "orders")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	<-server.subscriptions
+
+	ids := func() []string {
+		var ids []string
+		for _, record := range live.Records() {
+			ids = append(ids, record["id"].(string))
+		}
+		return ids
+	}
+
+	if !slices.Equal(ids(), []string{"order-1", "order-2"}) {
+		t.Errorf("Expected the initial records, got %v", ids())
+	}
+
+	server.events <- recordEvent("orders/*", "create", Record{"id": "order-3", "status": "open"})
+	<-live.Changes()
+	if !slices.Equal(ids(), []string{"order-1", "order-2", "order-3"}) {
+		t.Errorf("Expected the created record to be added, got %v", ids())
+	}
+
+	server.events <- recordEvent("orders/*", "update", Record{"id": "order-1", "status": "shipped"})
+	<-live.Changes()
+	if records := live.Records(); records[0]["status"] != "shipped" {
+		t.Errorf("Expected the updated record to be replaced, got %v", records[0])
+	}
+
+	server.events <- recordEvent("orders/*", "delete", Record{"id": "order-2"})
+	<-live.Changes()
+	if !slices.Equal(ids(), []string{"order-1", "order-3"}) {
+		t.Errorf("Expected the deleted record to be removed, got %v", ids())
+	}
+
+	// Modifying a returned record doesn't affect the cache
+	live.Records()[0]["status"] = "modified"
+	if live.Records()[0]["status"] != "shipped" {
+		t.Error("Expected the cached record to be unaffected by changes to a copy")
+	}
+
+	live.Close()
+	if _, ok := <-live.Changes(); ok {
+		t.Error("Expected the changes channel to be closed")
+	}
+}
+
+func TestClient_LiveQuery_RecordHook(t *testing.T) {
+	server := newMockRealtimeServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(listResp{
+			Page: 1, PerPage: 30, TotalItems: 1, TotalPages: 1,
+			Items: []Record{{"id": "order-1", "total": 10.0}},
+		})
+	})
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := NewClient(server.URL, WithRecordHook(func(record Record) Record {
+		total, _ := record["total"].(float64)
+		record["totalWithTax"] = total * 1.2
+		return record
+	}))
+
+	live, err := client.LiveQuery(ctx, "orders")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer live.Close()
+	<-server.subscriptions
+
+	server.events <- recordEvent("orders/*", "create", Record{"id": "order-2", "total": 20.0})
+	<-live.Changes()
+
+	records := live.Records()
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %v", records)
+	}
+	for _, record := range records {
+		if _, ok := record["totalWithTax"]; !ok {
+			t.Errorf("Expected every record to go through the hook, got %v", record)
+		}
+	}
+}
+
+func TestClient_LiveQuery_DefaultListOptions(t *testing.T) {
+	const expectedFilter = "(deleted = false) && (status = 'open')"
+
+	snapshotFilters := make(chan string, 1)
+	server := newMockRealtimeServer(t, func(w http.ResponseWriter, r *http.Request) {
+		snapshotFilters <- r.URL.Query().Get("filter")
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(listResp{Page: 1, PerPage: 30, TotalItems: 0, TotalPages: 1, Items: []Record{}})
+	})
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := NewClient(server.URL, WithDefaultListOptions(WithFilter("deleted = false")))
+
+	live, err := client.LiveQuery(ctx, "orders", WithFilter("status = 'open'"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer live.Close()
+
+	subscriptions := <-server.subscriptions
+	if len(subscriptions) != 1 {
+		t.Fatalf("Expected a single subscription, got %v", subscriptions)
+	}
+	topic, encoded, _ := strings.Cut(subscriptions[0], "?options=")
+	decoded, err := url.QueryUnescape(encoded)
+	if err != nil {
+		t.Fatalf("Failed to decode topic options: %v", err)
+	}
+	var topicOptions struct {
+		Query map[string]string `json:"query"`
+	}
+	if err := json.Unmarshal([]byte(decoded), &topicOptions); err != nil {
+		t.Fatalf("Failed to parse topic options: %v", err)
+	}
+	if topic != "orders/*" || topicOptions.Query["filter"] != expectedFilter {
+		t.Errorf("Expected topic orders/* with filter %q, got %s with %v", expectedFilter, topic, topicOptions.Query)
+	}
+
+	if filter := <-snapshotFilters; filter != expectedFilter {
+		t.Errorf("Expected snapshot filter %q, got %q", expectedFilter, filter)
+	}
+}
+
+func TestClient_LiveQuery_Reconnect(t *testing.T) {
+	const failedConnects = 15
+
+	var connects atomic.Int32
+	drop := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/realtime" && r.Method == "GET":
+			n := connects.Add(1)
+			if n > 1 && n <= failedConnects+1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "id:client-123\nevent:PB_CONNECT\ndata:{\"clientId\":\"client-123\"}\n\n")
+			w.(http.Flusher).Flush()
+
+			if n == 1 {
+				// Drop the first connection on demand to trigger the reconnects
+				select {
+				case <-drop:
+				case <-r.Context().Done():
+				}
+				return
+			}
+			<-r.Context().Done()
+		case r.URL.Path == "/api/realtime":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(listResp{
+				Page: 1, PerPage: 30, TotalItems: 1, TotalPages: 1,
+				Items: []Record{{"id": "order-1"}},
+			})
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var mu sync.Mutex
+	var retries []int
+	client := NewClient(server.URL, WithBackoff(func(retry int) time.Duration {
+		mu.Lock()
+		defer mu.Unlock()
+		retries = append(retries, retry)
+		return time.Millisecond
+	}))
+
+	live, err := client.LiveQuery(ctx, "orders")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer live.Close()
+
+	close(drop)
+	select {
+	case <-live.Changes():
+	case <-ctx.Done():
+		t.Fatal("Expected the live query to reconnect")
+	}
+
+	if n := connects.Load(); n != failedConnects+2 {
+		t.Errorf("Expected %d connection attempts, got %d", failedConnects+2, n)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(retries) != failedConnects+1 {
+		t.Fatalf("Expected %d backoff waits, got %d", failedConnects+1, len(retries))
+	}
+	for i, retry := range retries {
+		if expected := min(i+1, maxReconnectBackoffAttempt); retry != expected {
+			t.Errorf("Expected backoff attempt %d for reconnect %d, got %d", expected, i+1, retry)
+		}
+	}
+}
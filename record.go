@@ -0,0 +1,267 @@
+package pocketbase
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Diff returns a new Record containing only the fields of edited that differ from original,
+// suitable to pass to UpdateRecord so that only the changed fields are sent.
+// Values are compared deeply, including nested maps and slices, by their JSON
+// representation, so an int and a float64 with the same value are considered equal.
+// Fields present in original but missing from edited are included with a nil value,
+// which clears them on update.
+//
+// Example:
+//
+//	original, _ := client.GetRecord(ctx, "posts", "RECORD_ID")
+//	edited := maps.Clone(original)
+//	edited["title"] = "New title"
+//
+//	_, err := client.UpdateRecord(ctx, "posts", "RECORD_ID", pocketbase.Diff(original, edited))
+func Diff(original, edited Record) Record {
+	changes := Record{}
+
+	for key, value := range edited {
+		originalValue, ok := original[key]
+		if !ok || !valuesEqual(originalValue, value) {
+			changes[key] = value
+		}
+	}
+
+	for key := range original {
+		if _, ok := edited[key]; !ok {
+			changes[key] = nil
+		}
+	}
+
+	return changes
+}
+
+// valuesEqual reports whether two record values are deeply equal by comparing
+// their JSON encodings, falling back to reflect.DeepEqual if they can't be encoded.
+func valuesEqual(a, b any) bool {
+	aJSON, errA := json.Marshal(a)
+	bJSON, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return reflect.DeepEqual(a, b)
+	}
+	return bytes.Equal(aJSON, bJSON)
+}
+
+// readOnlyFields are the record fields managed by PocketBase that can't be set on update.
+var readOnlyFields = []string{"id", "created", "updated", "collectionId", "collectionName", "expand"}
+
+// ForUpdate returns a copy of the record without the fields managed by PocketBase
+// (id, created, updated, collectionId, collectionName and expand), which makes it safe
+// to send a fetched and modified record back with UpdateRecord.
+//
+// Example:
+//
+//	post, _ := client.GetRecord(ctx, "posts", "RECORD_ID", pocketbase.WithExpand("author"))
+//	post["title"] = "New title"
+//
+//	_, err := client.UpdateRecord(ctx, "posts", "RECORD_ID", post.ForUpdate())
+func (r Record) ForUpdate() Record {
+	updated := maps.Clone(r)
+	for _, field := range readOnlyFields {
+		delete(updated, field)
+	}
+	return updated
+}
+
+// FileNames returns the file names stored in a file field of the record.
+// Single-file fields hold a string and multi-file fields a list, both are normalized
+// into a slice. It returns nil when the field is missing or holds no files.
+//
+// Example:
+//
+//	for _, name := range record.FileNames("attachments") {
+//		fmt.Println(name)
+//	}
+func (r Record) FileNames(field string) []string {
+	return stringValues(r[field])
+}
+
+// stringValues normalizes a single or multiple value field (e.g. file or relation)
+// into a slice of its non-empty string values, returning nil when there are none.
+func stringValues(value any) []string {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	case []string:
+		if len(v) == 0 {
+			return nil
+		}
+		return v
+	case []any:
+		var values []string
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// Expand returns the expanded record of a single relation field, requested with
+// WithExpand. It returns nil if the field wasn't expanded or is a multi-relation
+// field, use ExpandList for those.
+//
+// Example:
+//
+//	post, _ := client.GetRecord(ctx, "posts", "RECORD_ID", pocketbase.WithExpand("author"))
+//	if author := post.Expand("author"); author != nil {
+//		fmt.Printf("Author: %s", author["name"])
+//	}
+func (r Record) Expand(field string) Record {
+	switch v := r.expanded(field).(type) {
+	case map[string]any:
+		return v
+	case Record:
+		return v
+	default:
+		return nil
+	}
+}
+
+// ExpandList returns the expanded records of a multi-relation field in the order
+// returned by PocketBase. An expanded single relation is returned as a one-element
+// slice, so the same code works for both. It returns nil if the field wasn't expanded.
+//
+// Example:
+//
+//	post, _ := client.GetRecord(ctx, "posts", "RECORD_ID", pocketbase.WithExpand("tags"))
+//	for _, tag := range post.ExpandList("tags") {
+//		fmt.Println(tag["name"])
+//	}
+func (r Record) ExpandList(field string) []Record {
+	switch v := r.expanded(field).(type) {
+	case []any:
+		records := make([]Record, 0, len(v))
+		for _, item := range v {
+			if record, ok := item.(map[string]any); ok {
+				records = append(records, record)
+			}
+		}
+		return records
+	case []Record:
+		return v
+	}
+
+	if record := r.Expand(field); record != nil {
+		return []Record{record}
+	}
+	return nil
+}
+
+// HiddenExpands reports which of the requested expands (as passed to WithExpand) are
+// missing from the record even though their relation field is set. PocketBase silently
+// omits the relations the user isn't allowed to view, so a missing expand with a set
+// relation usually means the related records are hidden by their view rule, while an
+// empty relation field simply means no relation is set. Nested expands like
+// "author.team" are checked on each expanded record and reported with their full path.
+//
+// Back-relations (e.g. "comments_via_post") and relation fields excluded with WithFields
+// aren't reported, as a missing expand can't be told apart from an empty relation there.
+//
+// Example:
+//
+//	expand := []string{"author", "tags", "author.team"}
+//	post, err := client.GetRecord(ctx, "posts", "RECORD_ID", pocketbase.WithExpand(expand...))
+//	if err != nil {
+//		return err
+//	}
+//	if hidden := post.HiddenExpands(expand...); len(hidden) > 0 {
+//		log.Printf("some related data is hidden: %v", hidden)
+//	}
+func (r Record) HiddenExpands(requested ...string) []string {
+	var hidden []string
+	for _, expand := range requested {
+		for path := range strings.SplitSeq(expand, ",") {
+			if path = strings.TrimSpace(path); path != "" {
+				hidden = r.appendHiddenExpands(hidden, "", path)
+			}
+		}
+	}
+	return slices.Compact(hidden)
+}
+
+// appendHiddenExpands appends the hidden expands of a dot separated expand path to
+// hidden, prefixing them with the path of the parent records.
+func (r Record) appendHiddenExpands(hidden []string, prefix, path string) []string {
+	field, rest, nested := strings.Cut(path, ".")
+	if r.expanded(field) == nil {
+		if stringValues(r[field]) != nil {
+			hidden = append(hidden, prefix+field)
+		}
+		return hidden
+	}
+
+	if nested {
+		for _, related := range r.ExpandList(field) {
+			hidden = related.appendHiddenExpands(hidden, prefix+field+".", rest)
+		}
+	}
+	return hidden
+}
+
+// expanded returns the raw expand value of a relation field.
+func (r Record) expanded(field string) any {
+	switch expand := r["expand"].(type) {
+	case map[string]any:
+		return expand[field]
+	case Record:
+		return expand[field]
+	default:
+		return nil
+	}
+}
+
+// SetJSONPath sets a value nested in a json field of the record, where path is a dot
+// separated list of keys (e.g. "author.name"). Missing or non-object values along the
+// path are replaced with objects. The maps along the path are copied rather than
+// modified, so records sharing nested values (e.g. a clone kept for Diff) are unaffected.
+//
+// PocketBase can't update part of a json field, the whole field value is replaced on
+// update. Send the complete field, which SetJSONPath builds from the current value.
+//
+// Example:
+//
+//	post, _ := client.GetRecord(ctx, "posts", "RECORD_ID")
+//	post.SetJSONPath("meta", "author.name", "Alice")
+//
+//	_, err := client.UpdateRecord(ctx, "posts", "RECORD_ID", pocketbase.Record{"meta": post["meta"]})
+func (r Record) SetJSONPath(field, path string, value any) {
+	r[field] = setJSONPath(r[field], strings.Split(path, "."), value)
+}
+
+// setJSONPath returns a copy of current with value set at the given keys.
+func setJSONPath(current any, keys []string, value any) any {
+	if len(keys) == 0 {
+		return value
+	}
+
+	var object map[string]any
+	switch v := current.(type) {
+	case map[string]any:
+		object = maps.Clone(v)
+	case Record:
+		object = maps.Clone(v)
+	default:
+		object = make(map[string]any)
+	}
+
+	object[keys[0]] = setJSONPath(object[keys[0]], keys[1:], value)
+	return object
+}
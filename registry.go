@@ -0,0 +1,132 @@
+package pocketbase
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// typeRegistry maps collections to the struct types their records are decoded into.
+type typeRegistry struct {
+	mu    sync.RWMutex
+	types map[string]reflect.Type
+}
+
+// RegisterType registers the type records of a collection are decoded into by
+// GetRecordTyped and GetAllRecordsTyped. The prototype is a value or pointer of the type,
+// typically a struct with json tags matching the collection fields. Registering a
+// collection again replaces its type. It is safe to call concurrently with requests.
+//
+// Example:
+//
+//	type Post struct {
+//		ID    string `json:"id"`
+//		Title string `json:"title"`
+//	}
+//
+//	client.RegisterType("posts", Post{})
+//
+//	record, err := client.GetRecordTyped(ctx, "posts", "RECORD_ID")
+//	if err != nil {
+//		return err
+//	}
+//	post := record.(*Post)
+func (c *Client) RegisterType(collection string, prototype any) {
+	t := reflect.TypeOf(prototype)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	c.registry.mu.Lock()
+	defer c.registry.mu.Unlock()
+
+	if c.registry.types == nil {
+		c.registry.types = make(map[string]reflect.Type)
+	}
+	if t == nil {
+		delete(c.registry.types, collection)
+		return
+	}
+	c.registry.types[collection] = t
+}
+
+// GetRecordTyped fetches a single record like GetRecord and decodes it into a new
+// instance of the type registered for the collection with RegisterType. The result is
+// a pointer to that type. An error is returned if no type is registered for the collection.
+func (c *Client) GetRecordTyped(ctx context.Context, collection, recordID string, opts ...QueryOption) (any, error) {
+	t, err := c.registeredType(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := c.GetRecord(ctx, collection, recordID, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.decodeRegistered(t, record)
+}
+
+// GetAllRecordsTyped fetches records like GetAllRecords and decodes each of them into a
+// new instance of the type registered for the collection with RegisterType. The items
+// are pointers to that type. An error is returned if no type is registered for the collection.
+//
+// Example:
+//
+//	client.RegisterType("posts", Post{})
+//
+//	items, err := client.GetAllRecordsTyped(ctx, "posts", pocketbase.WithSort("-created"))
+//	if err != nil {
+//		return err
+//	}
+//	for _, item := range items {
+//		fmt.Println(item.(*Post).Title)
+//	}
+func (c *Client) GetAllRecordsTyped(ctx context.Context, collection string, opts ...ListOption) ([]any, error) {
+	t, err := c.registeredType(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := c.GetAllRecords(ctx, collection, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]any, len(records))
+	for i, record := range records {
+		items[i], err = c.decodeRegistered(t, record)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return items, nil
+}
+
+// registeredType returns the type registered for a collection.
+func (c *Client) registeredType(collection string) (reflect.Type, error) {
+	c.registry.mu.RLock()
+	defer c.registry.mu.RUnlock()
+
+	t, ok := c.registry.types[collection]
+	if !ok {
+		return nil, fmt.Errorf("no type registered for collection %q", collection)
+	}
+	return t, nil
+}
+
+// decodeRegistered decodes a record into a new instance of t, returning a pointer to it.
+func (c *Client) decodeRegistered(t reflect.Type, record Record) (any, error) {
+	data, err := c.jsonMarshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode record: %w", err)
+	}
+
+	out := reflect.New(t).Interface()
+	if err := c.decodeTyped(data, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,203 @@
+package pocketbase
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// defaultChunkSize is the number of record IDs fetched per request by GetRecordsByIDs,
+// which keeps the generated filter within URL length limits.
+const defaultChunkSize = 50
+
+// RelationSpec describes a relation field to resolve client-side with ResolveRelation.
+type RelationSpec struct {
+	Collection string // Collection holding the relation field, empty to match records of any collection
+	Field      string // Name of the relation field
+	Target     string // Collection the relation points to
+}
+
+// ResolveRelation fetches the records referenced by a relation field of the given records,
+// with one request per batch of IDs rather than one per record, and adds them under
+// "expand" like the server-side WithExpand. This is useful when server-side expand isn't
+// an option, e.g. for records received from realtime events.
+// It returns copies of the records, the given records are not modified.
+//
+// Example:
+//
+//	posts, err = client.ResolveRelation(ctx, posts, pocketbase.RelationSpec{Field: "author", Target: "users"})
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Println(posts[0].Expand("author")["name"])
+func (c *Client) ResolveRelation(ctx context.Context, records []Record, spec RelationSpec) ([]Record, error) {
+	return c.ResolveRelationDeep(ctx, records, []RelationSpec{spec}, 1)
+}
+
+// ResolveRelationDeep resolves multiple levels of relations like ResolveRelation: the specs
+// are applied to the given records and then again to the related records, up to maxDepth
+// levels. Specs are matched by the "collectionName" field of each record.
+//
+// Each related record is fetched at most once, even when it is referenced several times
+// or through circular relations (A expands B which expands A), and the expansion stops at
+// maxDepth, so interconnected schemas can be resolved safely.
+//
+// Example:
+//
+//	// comments.post -> posts, posts.author -> users
+//	comments, err = client.ResolveRelationDeep(ctx, comments, []pocketbase.RelationSpec{
+//		{Collection: "comments", Field: "post", Target: "posts"},
+//		{Collection: "posts", Field: "author", Target: "users"},
+//	}, 2)
+//	author := comments[0].Expand("post").Expand("author")
+func (c *Client) ResolveRelationDeep(ctx context.Context, records []Record, specs []RelationSpec, maxDepth int) ([]Record, error) {
+	// Fetch the related records level by level, each record only once
+	fetched := make(map[string]map[string]Record) // collection -> id -> record
+	level := records
+	for depth := 0; depth < maxDepth && len(level) > 0; depth++ {
+		missing := make(map[string][]string) // collection -> ids
+		for _, record := range level {
+			for _, spec := range specs {
+				if !spec.matches(record) {
+					continue
+				}
+				for _, id := range stringValues(record[spec.Field]) {
+					if _, ok := fetched[spec.Target][id]; ok || slices.Contains(missing[spec.Target], id) {
+						continue
+					}
+					missing[spec.Target] = append(missing[spec.Target], id)
+				}
+			}
+		}
+
+		level = nil
+		for collection, ids := range missing {
+			related, err := c.GetRecordsByIDs(ctx, collection, ids)
+			if err != nil {
+				return nil, err
+			}
+			if fetched[collection] == nil {
+				fetched[collection] = make(map[string]Record)
+			}
+			for _, record := range related {
+				id, _ := record["id"].(string)
+				fetched[collection][id] = record
+				level = append(level, record)
+			}
+		}
+	}
+
+	// Build the expand trees from copies, so circular relations can't create cycles
+	resolved := make([]Record, len(records))
+	for i, record := range records {
+		resolved[i] = expandRelations(record, specs, fetched, maxDepth)
+	}
+
+	return resolved, nil
+}
+
+// matches reports whether the spec applies to the record.
+func (s RelationSpec) matches(record Record) bool {
+	if s.Collection == "" {
+		_, ok := record[s.Field]
+		return ok
+	}
+	return record["collectionName"] == s.Collection
+}
+
+// expandRelations returns a copy of record with its relations expanded from the
+// fetched records, down to the given number of levels.
+func expandRelations(record Record, specs []RelationSpec, fetched map[string]map[string]Record, levels int) Record {
+	expanded := maps.Clone(record)
+	if levels <= 0 {
+		return expanded
+	}
+
+	expand := make(map[string]any)
+	if existing, ok := record["expand"].(map[string]any); ok {
+		maps.Copy(expand, existing)
+	}
+
+	for _, spec := range specs {
+		if !spec.matches(record) {
+			continue
+		}
+
+		var related []any
+		for _, id := range stringValues(record[spec.Field]) {
+			if target, ok := fetched[spec.Target][id]; ok {
+				related = append(related, map[string]any(expandRelations(target, specs, fetched, levels-1)))
+			}
+		}
+
+		switch {
+		case len(related) == 0:
+		case isMultiRelation(record[spec.Field]):
+			expand[spec.Field] = related
+		default:
+			expand[spec.Field] = related[0]
+		}
+	}
+
+	if len(expand) > 0 {
+		expanded["expand"] = expand
+	}
+	return expanded
+}
+
+// GetRecordsByIDs fetches the records of a collection with the given IDs. Rather than
+// one request per record, the IDs are combined into an OR filter ("id = 'a' || id = 'b'
+// || ..."), split in chunks of 50 IDs (see WithChunkSize) so that the filter stays within
+// the URL length limits of PocketBase and the proxies in front of it. One list request is
+// sent per chunk and the records are returned in chunk order; IDs that don't exist are
+// skipped. Other list options such as WithFilter, WithListExpand and WithListFields are
+// applied to every chunk, and a sort only orders the records within a chunk.
+//
+// Example:
+//
+//	users, err := client.GetRecordsByIDs(ctx, "users", authorIDs, pocketbase.WithListFields("id", "name"))
+//	if err != nil {
+//		return err
+//	}
+func (c *Client) GetRecordsByIDs(ctx context.Context, collection string, ids []string, opts ...ListOption) ([]Record, error) {
+	options := c.newRecordListOptions(opts...)
+	ctx = withRequestUserAgent(ctx, options.UserAgent)
+
+	chunkSize := options.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+
+	var records []Record
+	for start := 0; start < len(ids); start += chunkSize {
+		chunk := ids[start:min(start+chunkSize, len(ids))]
+
+		conditions := make([]string, len(chunk))
+		for i, id := range chunk {
+			conditions[i] = Filter("id = {:id}", map[string]any{"id": id})
+		}
+
+		chunkOptions := *options
+		chunkOptions.Page = 0
+		chunkOptions.PerPage = min(chunkSize, maxPerPage)
+		chunkOptions.Filter = andFilters(options.Filter, strings.Join(conditions, " || "))
+
+		page, err := c.getAllRecords(ctx, collection, &chunkOptions)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, page...)
+	}
+	return records, nil
+}
+
+// isMultiRelation reports whether a relation field value holds a list of IDs.
+func isMultiRelation(value any) bool {
+	switch value.(type) {
+	case []any, []string:
+		return true
+	default:
+		return false
+	}
+}
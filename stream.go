@@ -0,0 +1,178 @@
+package pocketbase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// CreateResult is the outcome of creating a single record with CreateRecordsStream.
+type CreateResult struct {
+	Input  Record // The record read from the input channel
+	Record Record // The created record, nil on error
+	Err    error
+}
+
+// CreateRecordsStream creates the records read from the records channel using a pool of
+// concurrency workers, and emits the outcome of each creation on the returned channel.
+// Results are emitted in completion order, so use CreateResult.Input to match them with
+// the input records. The returned channel is closed once the input channel is closed and
+// all records have been processed, or once ctx is done.
+//
+// The input channel is only read as fast as the workers create records and their results
+// are received, so a slow consumer throttles the ingestion instead of flooding the server.
+// A creation rejected with 429 Too Many Requests is retried after the wait requested by the
+// server, like the pagination of GetAllRecords. Other failures are reported in the result.
+//
+// Example:
+//
+//	records := make(chan pocketbase.Record)
+//	go func() {
+//		defer close(records)
+//		for _, row := range rows {
+//			records <- pocketbase.Record{"name": row.Name}
+//		}
+//	}()
+//
+//	results, err := client.CreateRecordsStream(ctx, "products", records, 8)
+//	if err != nil {
+//		return err
+//	}
+//	for result := range results {
+//		if result.Err != nil {
+//			log.Printf("failed to import %v: %v", result.Input["name"], result.Err)
+//		}
+//	}
+func (c *Client) CreateRecordsStream(ctx context.Context, collection string, records <-chan Record, concurrency int) (<-chan CreateResult, error) {
+	if concurrency <= 0 {
+		return nil, fmt.Errorf("concurrency must be greater than zero, got %d", concurrency)
+	}
+
+	results := make(chan CreateResult)
+
+	var wg sync.WaitGroup
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				var record Record
+				var ok bool
+				select {
+				case record, ok = <-records:
+					if !ok {
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+
+				created, err := c.createRecordWithRateLimit(ctx, collection, record)
+				select {
+				case results <- CreateResult{Input: record, Record: created, Err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results, nil
+}
+
+// createRecordWithRateLimit creates a record, waiting and retrying when the server responds
+// with 429 Too Many Requests. The rejected request had no effect, so retrying it is safe.
+func (c *Client) createRecordWithRateLimit(ctx context.Context, collection string, record Record) (Record, error) {
+	for attempt := 0; ; attempt++ {
+		created, err := c.CreateRecord(ctx, collection, record)
+
+		var apiErr *APIError
+		if err == nil || !errors.As(err, &apiErr) || !apiErr.IsTooManyRequests() || attempt >= maxRateLimitRetries {
+			return created, err
+		}
+
+		wait := apiErr.retryAfter
+		if wait <= 0 {
+			wait = defaultRateLimitWait
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// ProcessAllRecords fetches the records of a collection matching the list options a page
+// at a time and runs fn on each of them with a pool of workers goroutines, so large
+// collections can be processed concurrently without being loaded in memory at once. The
+// next page is only fetched once the workers have picked up the records of the current
+// one, so memory use stays bounded by the page size.
+//
+// Processing stops at the first error, returned by fn or by fetching a page: the records
+// not yet dispatched are skipped, the workers finish the records in progress and the
+// error is returned. fn must be safe for concurrent use.
+//
+// Example:
+//
+//	err := client.ProcessAllRecords(ctx, "orders", 8, func(order pocketbase.Record) error {
+//		return warehouse.Insert(ctx, order)
+//	}, pocketbase.WithFilter("status = 'paid'"), pocketbase.WithPerPage(500))
+//	if err != nil {
+//		return err
+//	}
+func (c *Client) ProcessAllRecords(ctx context.Context, collection string, workers int, fn func(Record) error, opts ...ListOption) error {
+	if workers <= 0 {
+		return fmt.Errorf("workers must be greater than zero, got %d", workers)
+	}
+
+	options := c.newRecordListOptions(opts...)
+	ctx = withRequestUserAgent(ctx, options.UserAgent)
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	records := make(chan Record)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for record := range records {
+				if ctx.Err() != nil {
+					continue
+				}
+				if err := fn(record); err != nil {
+					id, _ := record["id"].(string)
+					cancel(fmt.Errorf("failed to process record %s: %w", id, err))
+				}
+			}
+		}()
+	}
+
+	options.PageCallback = func(page []Record) error {
+		for _, record := range page {
+			select {
+			case records <- record:
+			case <-ctx.Done():
+				return context.Cause(ctx)
+			}
+		}
+		return nil
+	}
+
+	_, err := c.getAllRecords(ctx, collection, options)
+	close(records)
+	wg.Wait()
+
+	// An error of fn is the cause of the fetch error, if any
+	if cause := context.Cause(ctx); cause != nil && ctx.Err() != nil {
+		return cause
+	}
+	return err
+}
@@ -0,0 +1,99 @@
+package pocketbase
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// superusersCollection is the system auth collection of the PocketBase superusers.
+const superusersCollection = "_superusers"
+
+// SuperuserService groups the operations that require superuser authentication, see
+// Client.Superuser. Its methods delegate to the client methods of the same name, but fail
+// with an error wrapping ErrSuperuserRequired without sending a request when the client
+// is known not to be authenticated as a superuser.
+type SuperuserService struct {
+	client *Client
+}
+
+// Superuser returns the operations requiring superuser authentication, making explicit
+// at the call site that a request needs elevated privileges.
+//
+// Example:
+//
+//	if _, err := client.AuthenticateAsSuperuser(ctx, "admin@example.com", "password"); err != nil {
+//		return err
+//	}
+//
+//	logs, err := client.Superuser().GetLogs(ctx, pocketbase.WithFilter("level > 0"))
+//	if err != nil {
+//		return err
+//	}
+func (c *Client) Superuser() *SuperuserService {
+	return &SuperuserService{client: c}
+}
+
+// Impersonate generates a non-refreshable auth token for another record, see Client.Impersonate.
+func (s *SuperuserService) Impersonate(ctx context.Context, collection, recordID string, duration int, opts ...ImpersonateOption) (*ImpersonateResult, error) {
+	if err := s.checkAuth(ctx); err != nil {
+		return nil, err
+	}
+	return s.client.Impersonate(ctx, collection, recordID, duration, opts...)
+}
+
+// GetLogs fetches a single page of log entries, see Client.GetLogs.
+func (s *SuperuserService) GetLogs(ctx context.Context, opts ...ListOption) ([]Record, error) {
+	if err := s.checkAuth(ctx); err != nil {
+		return nil, err
+	}
+	return s.client.GetLogs(ctx, opts...)
+}
+
+// GetAllLogs fetches all log entries matching the list options, see Client.GetAllLogs.
+func (s *SuperuserService) GetAllLogs(ctx context.Context, opts ...ListOption) ([]Record, error) {
+	if err := s.checkAuth(ctx); err != nil {
+		return nil, err
+	}
+	return s.client.GetAllLogs(ctx, opts...)
+}
+
+// TruncateCollection deletes all records of a collection, see Client.TruncateCollectionFast.
+func (s *SuperuserService) TruncateCollection(ctx context.Context, collection string) error {
+	if err := s.checkAuth(ctx); err != nil {
+		return err
+	}
+	return s.client.TruncateCollectionFast(ctx, collection)
+}
+
+// ImportCollection imports NDJSON records into a collection, see Client.ImportCollection.
+// Restoring an export as is requires superuser authentication, as regular users usually
+// can't choose record IDs.
+func (s *SuperuserService) ImportCollection(ctx context.Context, collection string, r io.Reader, opts ...ImportOption) (imported int, failed int, err error) {
+	if err := s.checkAuth(ctx); err != nil {
+		return 0, 0, err
+	}
+	return s.client.ImportCollection(ctx, collection, r, opts...)
+}
+
+// checkAuth returns an error wrapping ErrSuperuserRequired when the request would be sent
+// without a token, or with the token of an auth record that isn't a superuser. A token
+// set with ContextWithToken or SetToken can't be checked and is left to the server.
+func (s *SuperuserService) checkAuth(ctx context.Context) error {
+	if token, ok := ctx.Value(requestTokenKey{}).(string); ok {
+		if token == "" {
+			return fmt.Errorf("%w: no auth token", ErrSuperuserRequired)
+		}
+		return nil
+	}
+
+	if s.client.GetToken() == "" {
+		return fmt.Errorf("%w: no auth token", ErrSuperuserRequired)
+	}
+	if record := s.client.AuthRecord(); record != nil {
+		if name, _ := record["collectionName"].(string); name != "" && name != superusersCollection {
+			return fmt.Errorf("%w: authenticated as a %q record", ErrSuperuserRequired, name)
+		}
+	}
+	return nil
+}
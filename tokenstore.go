@@ -0,0 +1,65 @@
+package pocketbase
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// superuserTokenLeeway is how long before its expiry a shared superuser token is
+// replaced, so it doesn't expire in the middle of an operation.
+const superuserTokenLeeway = time.Minute
+
+// SuperuserTokenStore shares superuser tokens between clients, so tools creating many
+// clients authenticate once instead of once per client. Use it with WithSharedSuperuserAuth.
+// A store is safe for concurrent use. Tokens are kept in memory only.
+type SuperuserTokenStore struct {
+	mu      sync.Mutex
+	entries map[string]superuserAuth
+}
+
+// superuserAuth is a superuser token shared through a SuperuserTokenStore.
+type superuserAuth struct {
+	token  string
+	record Record
+}
+
+// NewSuperuserTokenStore creates an empty superuser token store.
+func NewSuperuserTokenStore() *SuperuserTokenStore {
+	return &SuperuserTokenStore{entries: make(map[string]superuserAuth)}
+}
+
+// authenticate returns the stored auth for key if valid reports its token as still usable,
+// and otherwise calls login and stores the result. The store stays locked during login,
+// so concurrent callers wait for a single login instead of each logging in.
+func (s *SuperuserTokenStore) authenticate(key string, valid func(token string) bool, login func() (superuserAuth, error)) (superuserAuth, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if auth, ok := s.entries[key]; ok && valid(auth.token) {
+		return auth, nil
+	}
+
+	auth, err := login()
+	if err != nil {
+		return superuserAuth{}, err
+	}
+	s.entries[key] = auth
+
+	return auth, nil
+}
+
+// superuserStoreKey identifies a superuser login in a SuperuserTokenStore. The password
+// is part of the key, so a token is never handed out for different credentials.
+func superuserStoreKey(baseURL, email, password string) string {
+	hash := sha256.Sum256([]byte(password))
+	return baseURL + "\x00" + email + "\x00" + hex.EncodeToString(hash[:])
+}
+
+// sharedTokenValid reports whether a shared token can still be used, based on its
+// expiry on the server clock.
+func (c *Client) sharedTokenValid(token string) bool {
+	expiry, ok := tokenExpiry(token)
+	return ok && c.serverNow().Add(superuserTokenLeeway).Before(expiry)
+}
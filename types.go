@@ -1,6 +1,9 @@
 package pocketbase
 
-import "io"
+import (
+	"io"
+	"strings"
+)
 
 // Record represents a generic PocketBase record as a map of field names to values.
 // This flexible structure allows handling different collection schemas dynamically.
@@ -13,6 +16,12 @@ type FileData struct {
 	Size     int64
 }
 
+// RawBody represents a pre-encoded request body that is sent without JSON encoding.
+type RawBody struct {
+	Reader      io.Reader
+	ContentType string // Defaults to "application/octet-stream"
+}
+
 // FileUpload represents file upload configuration for a field
 type FileUpload struct {
 	Field  string
@@ -71,10 +80,60 @@ func WithDelete(filenames ...string) FileUploadModifier {
 	}
 }
 
+// DownloadOption represents functional options for file downloads.
+type DownloadOption func(*DownloadOptions)
+
+// DownloadOptions holds the settings of a file download.
+type DownloadOptions struct {
+	// Byte range to download, see WithRange
+	HasRange   bool
+	RangeStart int64
+	RangeEnd   int64 // Inclusive, negative for the rest of the file
+}
+
+// WithRange downloads only the bytes from start to end of the file, both inclusive,
+// e.g. to resume an interrupted download. Pass a negative end to download from start
+// to the end of the file.
+func WithRange(start, end int64) DownloadOption {
+	return func(opts *DownloadOptions) {
+		opts.HasRange = true
+		opts.RangeStart = start
+		opts.RangeEnd = end
+	}
+}
+
 // authResp represents the response structure from the auth-with-password endpoint.
 type authResp struct {
-	Token  string `json:"token"`
-	Record Record `json:"record"`
+	Token  string         `json:"token"`
+	Record Record         `json:"record"`
+	Meta   map[string]any `json:"meta,omitempty"`
+}
+
+// AuthResult contains the result of an authentication request.
+type AuthResult struct {
+	Token  string
+	Record Record
+
+	// Meta holds the OAuth2 provider data of an OAuth2 login, such as "name", "email",
+	// "avatarURL", "isNew" (true for a newly created account) and "rawUser". It is nil
+	// for other authentication methods.
+	Meta map[string]any
+}
+
+// authMethodsResp represents the response structure from the auth-methods endpoint.
+type authMethodsResp struct {
+	OAuth2 struct {
+		Enabled   bool                 `json:"enabled"`
+		Providers []oauth2ProviderInfo `json:"providers"`
+	} `json:"oauth2"`
+}
+
+// oauth2ProviderInfo represents an OAuth2 provider listed by the auth-methods endpoint.
+type oauth2ProviderInfo struct {
+	Name         string `json:"name"`
+	State        string `json:"state"`
+	AuthURL      string `json:"authURL"`
+	CodeVerifier string `json:"codeVerifier"`
 }
 
 // listResp represents the paginated response structure from the list records endpoint.
@@ -103,6 +162,10 @@ type impersonateResp struct {
 type ImpersonateResult struct {
 	Token  string
 	Record Record
+
+	// ImpersonatorID is the ID of the superuser who requested the impersonation, read
+	// from the auth token of the request. It is empty when the token can't be decoded.
+	ImpersonatorID string
 }
 
 // QueryOption represents functional options for single record queries.
@@ -110,8 +173,13 @@ type QueryOption func(*QueryOptions)
 
 // QueryOptions holds query parameters for single record requests.
 type QueryOptions struct {
-	Expand []string
-	Fields []string
+	Expand       []string
+	Fields       []string
+	FieldsPreset string // Name of a field preset registered with WithFieldPreset
+
+	UserAgent       string // Overrides the client User-Agent for this call
+	MinimalResponse bool   // Only the record ID is returned in the response
+	ExpectedUpdated string // Updates fail with ErrUpdateConflict if the record "updated" field differs
 }
 
 // ListOption represents functional options for list queries.
@@ -125,6 +193,15 @@ type ListOptions struct {
 	Filter  string
 	Expand  []string
 	Fields  []string
+
+	FieldsPreset string // Name of a field preset registered with WithFieldPreset
+	UserAgent    string // Overrides the client User-Agent for this call
+	MaxPages     int    // Maximum number of pages fetched, 10000 when not set
+	SkipTotal    bool   // The server skips counting the total items and pages
+	ChunkSize    int    // Number of IDs per request of GetRecordsByIDs, 50 when not set
+
+	// PageCallback receives each fetched page instead of collecting all items in memory
+	PageCallback func(items []Record) error
 }
 
 // WithExpand adds expand fields to query options.
@@ -141,6 +218,221 @@ func WithFields(fields ...string) QueryOption {
 	}
 }
 
+// ExpandFields returns the field selectors limiting an expanded relation to the given
+// fields, for use with WithFields or WithListFields together with WithExpand. The relation
+// may be a nested expand path like "author.team", whose fields live under the expand of
+// each parent record. Without fields, the whole expanded relation is selected.
+//
+// Example:
+//
+//	fields := append([]string{"id", "title"}, pocketbase.ExpandFields("author", "name", "avatar")...)
+//	post, err := client.GetRecord(ctx, "posts", "RECORD_ID",
+//		pocketbase.WithExpand("author"),
+//		pocketbase.WithFields(fields...)) // id,title,expand.author.name,expand.author.avatar
+func ExpandFields(relation string, fields ...string) []string {
+	prefix := "expand." + strings.ReplaceAll(relation, ".", ".expand.")
+	if len(fields) == 0 {
+		return []string{prefix}
+	}
+
+	selectors := make([]string, len(fields))
+	for i, field := range fields {
+		selectors[i] = prefix + "." + field
+	}
+	return selectors
+}
+
+// WithFieldsPreset selects the fields of a preset registered with WithFieldPreset.
+// An unknown preset name makes the request fail with an error.
+func WithFieldsPreset(name string) QueryOption {
+	return func(opts *QueryOptions) {
+		opts.FieldsPreset = name
+	}
+}
+
+// BulkOption represents functional options for bulk operations such as UpdateRecordsByFilter.
+type BulkOption func(*BulkOptions)
+
+// BulkOptions holds the options of a bulk operation.
+type BulkOptions struct {
+	ContinueOnError bool // Keep going after a failed record instead of stopping
+	QueryOptions         // Applied to each request of the operation
+}
+
+// WithBulkQuery applies query options such as WithRequestUserAgent to each request of a
+// bulk operation.
+//
+// Example:
+//
+//	updated, err := client.UpdateRecordsByFilter(ctx, "posts", filter, patch,
+//		pocketbase.WithBulkQuery(pocketbase.WithMinimalResponse()))
+func WithBulkQuery(opts ...QueryOption) BulkOption {
+	return func(options *BulkOptions) {
+		for _, opt := range opts {
+			opt(&options.QueryOptions)
+		}
+	}
+}
+
+// WithContinueOnError makes bulk operations such as UpdateRecordsByFilter continue with
+// the remaining records when one fails, instead of stopping at the first failure.
+// The failures are returned joined into a single error.
+func WithContinueOnError() BulkOption {
+	return func(opts *BulkOptions) {
+		opts.ContinueOnError = true
+	}
+}
+
+// WithRequestUserAgent overrides the client User-Agent header for a single call,
+// e.g. to tag the requests of a sub-service sharing the client.
+func WithRequestUserAgent(userAgent string) QueryOption {
+	return func(opts *QueryOptions) {
+		opts.UserAgent = userAgent
+	}
+}
+
+// WithMinimalResponse makes the server return only the record ID, which saves bandwidth
+// and decoding work for high-volume writes where the created or updated record isn't needed.
+// PocketBase doesn't support the "Prefer: return=minimal" header, so this is done by
+// requesting fields=id, and it overrides WithFields and WithFieldsPreset. Expand is
+// still applied on the server, so don't combine it with WithExpand.
+//
+// Example:
+//
+//	created, err := client.CreateRecord(ctx, "events", event, pocketbase.WithMinimalResponse())
+//	fmt.Println(created["id"]) // the only field returned
+func WithMinimalResponse() QueryOption {
+	return func(opts *QueryOptions) {
+		opts.MinimalResponse = true
+	}
+}
+
+// WithExpectedUpdated makes UpdateRecord fail with an error wrapping ErrUpdateConflict
+// when the "updated" field of the record isn't the given timestamp, i.e. the record was
+// modified since it was read, to avoid overwriting someone else's changes.
+//
+// PocketBase has no conditional update, so the record "updated" field is fetched and
+// compared right before sending the update. This detects nearly all lost updates, but
+// isn't atomic: a change landing between the check and the update isn't detected.
+// For strict guarantees, enforce the condition in an update API rule or a server hook.
+//
+// Example:
+//
+//	post, _ := client.GetRecord(ctx, "posts", id)
+//	post["title"] = "New title"
+//	_, err := client.UpdateRecord(ctx, "posts", id, post.ForUpdate(),
+//		pocketbase.WithExpectedUpdated(post["updated"].(string)))
+//	if errors.Is(err, pocketbase.ErrUpdateConflict) {
+//		// Reload the record and ask the user to merge the changes
+//	}
+func WithExpectedUpdated(timestamp string) QueryOption {
+	return func(opts *QueryOptions) {
+		opts.ExpectedUpdated = timestamp
+	}
+}
+
+// FileURLOption represents functional options for GetRecordWithFileURLs.
+type FileURLOption func(*FileURLOptions)
+
+// FileURLOptions holds the options of GetRecordWithFileURLs.
+type FileURLOptions struct {
+	// FileFields and ProtectedFileFields name the file fields of the record, which are
+	// otherwise read from the collection schema
+	FileFields          []string
+	ProtectedFileFields []string
+	QueryOptions
+}
+
+// WithFileURLQuery applies query options such as WithExpand and WithFields to the record
+// request of GetRecordWithFileURLs.
+//
+// Example:
+//
+//	post, err := client.GetRecordWithFileURLs(ctx, "posts", "RECORD_ID",
+//		pocketbase.WithFileURLQuery(pocketbase.WithFields("id", "title", "cover")))
+func WithFileURLQuery(opts ...QueryOption) FileURLOption {
+	return func(options *FileURLOptions) {
+		for _, opt := range opts {
+			opt(&options.QueryOptions)
+		}
+	}
+}
+
+// WithFileFields names the file fields whose filenames GetRecordWithFileURLs replaces with
+// URLs, so it doesn't read them from the collection schema, which requires superuser
+// authentication. Use WithProtectedFileFields for the protected ones.
+//
+// Example:
+//
+//	post, err := client.GetRecordWithFileURLs(ctx, "posts", "RECORD_ID",
+//		pocketbase.WithFileFields("cover", "gallery"))
+func WithFileFields(fields ...string) FileURLOption {
+	return func(opts *FileURLOptions) {
+		opts.FileFields = append(opts.FileFields, fields...)
+	}
+}
+
+// WithProtectedFileFields names the protected file fields of GetRecordWithFileURLs, whose
+// URLs include a file token, like WithFileFields does for the public ones.
+func WithProtectedFileFields(fields ...string) FileURLOption {
+	return func(opts *FileURLOptions) {
+		opts.ProtectedFileFields = append(opts.ProtectedFileFields, fields...)
+	}
+}
+
+// ImpersonateOption represents functional options for Impersonate.
+type ImpersonateOption func(*ImpersonateOptions)
+
+// ImpersonateOptions holds the options of an Impersonate request.
+type ImpersonateOptions struct {
+	Metadata map[string]any // Extra fields sent in the request body
+	QueryOptions
+}
+
+// WithImpersonateQuery applies query options such as WithExpand and WithFields to an
+// Impersonate request, e.g. to expand relations of the returned auth record.
+//
+// Example:
+//
+//	result, err := client.Impersonate(ctx, "users", "USER_ID", 3600,
+//		pocketbase.WithImpersonateQuery(pocketbase.WithExpand("profile")))
+func WithImpersonateQuery(opts ...QueryOption) ImpersonateOption {
+	return func(options *ImpersonateOptions) {
+		for _, opt := range opts {
+			opt(&options.QueryOptions)
+		}
+	}
+}
+
+// WithImpersonationReason sends the reason of an impersonation in the "reason" body
+// field of the Impersonate request, see WithImpersonationMetadata.
+//
+// Example:
+//
+//	result, err := client.Impersonate(ctx, "users", "USER_ID", 3600,
+//		pocketbase.WithImpersonationReason("support ticket #1234"))
+func WithImpersonationReason(reason string) ImpersonateOption {
+	return WithImpersonationMetadata("reason", reason)
+}
+
+// WithImpersonationMetadata adds a field to the body of the Impersonate request. PocketBase
+// ignores unknown fields, but server hooks (e.g. onRecordImpersonateRequest) can read them
+// to record who impersonated whom and why in an audit log.
+//
+// Example:
+//
+//	result, err := client.Impersonate(ctx, "users", "USER_ID", 3600,
+//		pocketbase.WithImpersonationReason("support ticket #1234"),
+//		pocketbase.WithImpersonationMetadata("ticket", 1234))
+func WithImpersonationMetadata(key string, value any) ImpersonateOption {
+	return func(opts *ImpersonateOptions) {
+		if opts.Metadata == nil {
+			opts.Metadata = make(map[string]any)
+		}
+		opts.Metadata[key] = value
+	}
+}
+
 // WithSort adds sorting to list options.
 func WithSort(sort string) ListOption {
 	return func(opts *ListOptions) {
@@ -169,6 +461,68 @@ func WithListFields(fields ...string) ListOption {
 	}
 }
 
+// WithListFieldsPreset selects the fields of a preset registered with WithFieldPreset for list options.
+// An unknown preset name makes the request fail with an error.
+func WithListFieldsPreset(name string) ListOption {
+	return func(opts *ListOptions) {
+		opts.FieldsPreset = name
+	}
+}
+
+// WithListRequestUserAgent overrides the client User-Agent header for a single list call,
+// including every page request it makes.
+func WithListRequestUserAgent(userAgent string) ListOption {
+	return func(opts *ListOptions) {
+		opts.UserAgent = userAgent
+	}
+}
+
+// WithPageCallback streams the pages fetched by paginated calls such as GetAllRecords and
+// GetAllLogs to fn as they arrive, instead of collecting every item in memory. The call then
+// returns no items. Returning an error from fn stops the pagination and returns that error.
+//
+// Example:
+//
+//	_, err := client.GetAllLogs(ctx, pocketbase.WithPageCallback(func(logs []pocketbase.Record) error {
+//		return encoder.Encode(logs)
+//	}))
+func WithPageCallback(fn func(items []Record) error) ListOption {
+	return func(opts *ListOptions) {
+		opts.PageCallback = fn
+	}
+}
+
+// WithMaxPages caps the number of page requests made by paginated calls such as
+// GetAllRecords. When more pages remain after n pages, the call fails with an error
+// wrapping ErrMaxPagesExceeded instead of paginating forever, e.g. when a buggy server
+// reports wrong totals. The default is 10000 pages.
+func WithMaxPages(n int) ListOption {
+	return func(opts *ListOptions) {
+		opts.MaxPages = n
+	}
+}
+
+// WithSkipTotal makes the server skip counting the total number of matching items, which
+// speeds up list requests on large collections. Paginated calls then stop at the first
+// page that isn't full, and GetList reports -1 for TotalItems and TotalPages.
+// CountRecords always counts, even when WithSkipTotal is a client default.
+func WithSkipTotal() ListOption {
+	return func(opts *ListOptions) {
+		opts.SkipTotal = true
+	}
+}
+
+// WithChunkSize sets how many IDs GetRecordsByIDs puts in the filter of a single list
+// request. The default of 50 keeps the request URL around 2KB. Larger chunks mean fewer
+// requests, but the filter grows by roughly 40 URL-encoded characters per ID: many
+// proxies and servers reject URLs over 8KB, and PocketBase rejects overly long or complex
+// filters, so chunks of more than about 100 IDs are likely to fail.
+func WithChunkSize(n int) ListOption {
+	return func(opts *ListOptions) {
+		opts.ChunkSize = n
+	}
+}
+
 // WithPage sets the page number for list options.
 func WithPage(page int) ListOption {
 	return func(opts *ListOptions) {